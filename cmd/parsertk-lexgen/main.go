@@ -0,0 +1,54 @@
+// Command parsertk-lexgen compiles a JSON lexer specification into a Go source
+// file containing a table-driven DFA lexer. It is meant to be run from a
+// go:generate directive:
+//
+//	//go:generate go run github.com/mtrense/parsertk/cmd/parsertk-lexgen -spec lexer.json -o lexer_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mtrense/parsertk/lexer/lexgen"
+)
+
+func main() {
+	specFile := flag.String("spec", "", "lexer specification (JSON)")
+	output := flag.String("o", "", "output file (default stdout)")
+	pkg := flag.String("package", "", "override the package of the generated file")
+	flag.Parse()
+	if *specFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*specFile, *output, *pkg); err != nil {
+		fmt.Fprintf(os.Stderr, "parsertk-lexgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specFile, output, pkg string) error {
+	f, err := os.Open(specFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	spec, err := lexgen.LoadSpec(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specFile, err)
+	}
+	if pkg != "" {
+		spec.Package = pkg
+	}
+	source, err := lexgen.Generate(spec)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specFile, err)
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return ioutil.WriteFile(output, source, 0644)
+}
@@ -8,4 +8,5 @@ require (
 	github.com/onsi/gomega v1.10.1
 	github.com/rs/zerolog v1.19.0
 	github.com/spf13/viper v1.7.1
+	golang.org/x/text v0.3.2
 )
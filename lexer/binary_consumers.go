@@ -0,0 +1,235 @@
+package lexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ByteConsumer is the binary counterpart of TokenConsumer. The Value of the
+// returned Token holds the raw bytes consumed and Data their decoded value.
+type ByteConsumer func(BufferedByteReader) (Token, bool)
+
+// LexBinary scans binary input using one fixed set of valid Tokens, like
+// LexStatic does for text. Token offsets are byte offsets.
+func LexBinary(input BufferedByteReader, visitor Visitor, eofToken, errorToken TokenType, validTokens ...ByteConsumer) {
+	for {
+		tok, rule := scan(input, eofToken, errorToken, len(validTokens), func(i int) (Token, bool) {
+			return validTokens[i](input)
+		})
+		visitor(tok)
+		if rule < 0 {
+			break
+		}
+	}
+}
+
+// ConsumeUint consumes an unsigned integer of width 1, 2, 4 or 8 bytes in the
+// given byte order. Data holds the value as uint64.
+func ConsumeUint(typ TokenType, width int, order binary.ByteOrder) ByteConsumer {
+	decode := fixedWidthDecoder(width, order)
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		raw, ok := readBytes(input, width)
+		if !ok {
+			return Token{}, false
+		}
+		return tb(typ, raw, decode(raw)), true
+	})
+}
+
+// ConsumeInt consumes a two's complement signed integer of width 1, 2, 4 or 8
+// bytes in the given byte order. Data holds the value as int64.
+func ConsumeInt(typ TokenType, width int, order binary.ByteOrder) ByteConsumer {
+	decode := fixedWidthDecoder(width, order)
+	shift := uint(64 - 8*width)
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		raw, ok := readBytes(input, width)
+		if !ok {
+			return Token{}, false
+		}
+		return tb(typ, raw, int64(decode(raw)<<shift)>>shift), true
+	})
+}
+
+func fixedWidthDecoder(width int, order binary.ByteOrder) func([]byte) uint64 {
+	switch width {
+	case 1:
+		return func(raw []byte) uint64 { return uint64(raw[0]) }
+	case 2:
+		return func(raw []byte) uint64 { return uint64(order.Uint16(raw)) }
+	case 4:
+		return func(raw []byte) uint64 { return uint64(order.Uint32(raw)) }
+	case 8:
+		return order.Uint64
+	}
+	panic(fmt.Sprintf("lexer: unsupported integer width %d", width))
+}
+
+// ConsumeUvarint consumes an unsigned LEB128 integer, which is also the
+// encoding of protobuf varints. Data holds the value as uint64. Encodings
+// overflowing 64 bits do not match.
+func ConsumeUvarint(typ TokenType) ByteConsumer {
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		raw, value, ok := readUvarint(input)
+		if !ok {
+			return Token{}, false
+		}
+		return tb(typ, raw, value), true
+	})
+}
+
+// ConsumeZigZagVarint consumes a protobuf sint varint. Data holds the value as
+// int64.
+func ConsumeZigZagVarint(typ TokenType) ByteConsumer {
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		raw, value, ok := readUvarint(input)
+		if !ok {
+			return Token{}, false
+		}
+		return tb(typ, raw, int64(value>>1)^-int64(value&1)), true
+	})
+}
+
+// ConsumeSLEB128 consumes a signed LEB128 integer as used by DWARF and
+// WebAssembly. Data holds the value as int64. Encodings overflowing 64 bits do
+// not match.
+func ConsumeSLEB128(typ TokenType) ByteConsumer {
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		var (
+			value int64
+			shift uint
+			raw   []byte
+		)
+		for {
+			if input.EOF() || shift >= 64 {
+				return Token{}, false
+			}
+			b := input.Read()
+			raw = append(raw, b)
+			// The last byte holds bit 63, the other bits must repeat it.
+			if shift == 63 && b != 0x00 && b != 0x7f {
+				return Token{}, false
+			}
+			value |= int64(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				if shift < 64 && b&0x40 != 0 {
+					value |= -1 << shift
+				}
+				return tb(typ, raw, value), true
+			}
+		}
+	})
+}
+
+func readUvarint(input BufferedByteReader) ([]byte, uint64, bool) {
+	var (
+		value uint64
+		shift uint
+		raw   []byte
+	)
+	for {
+		if input.EOF() || shift >= 64 {
+			return nil, 0, false
+		}
+		b := input.Read()
+		raw = append(raw, b)
+		if shift == 63 && b > 1 {
+			return nil, 0, false
+		}
+		value |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			return raw, value, true
+		}
+	}
+}
+
+// ConsumeLengthPrefixed consumes a blob whose length in bytes is given by the
+// unsigned integer token matched by prefix, e.g. ConsumeUvarint or ConsumeUint.
+// Value holds prefix and payload, Data the payload as string.
+func ConsumeLengthPrefixed(typ TokenType, prefix ByteConsumer) ByteConsumer {
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		length, ok := prefix(input)
+		if !ok {
+			return Token{}, false
+		}
+		size, ok := length.Data.(uint64)
+		if !ok || size > uint64(maxInt) {
+			return Token{}, false
+		}
+		payload, ok := readBytes(input, int(size))
+		if !ok {
+			return Token{}, false
+		}
+		return tb(typ, append([]byte(length.Value), payload...), string(payload)), true
+	})
+}
+
+// ConsumeMagic consumes the exact byte sequence magic.
+func ConsumeMagic(typ TokenType, magic []byte) ByteConsumer {
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		raw, ok := readBytes(input, len(magic))
+		if !ok || !bytes.Equal(raw, magic) {
+			return Token{}, false
+		}
+		return tb(typ, raw, nil), true
+	})
+}
+
+// ConsumeCString consumes a null terminated string. Value includes the
+// terminating null byte, Data holds the string without it.
+func ConsumeCString(typ TokenType) ByteConsumer {
+	return transactionalBytes(func(input BufferedByteReader) (Token, bool) {
+		var raw []byte
+		for {
+			if input.EOF() {
+				return Token{}, false
+			}
+			b := input.Read()
+			raw = append(raw, b)
+			if b == 0 {
+				return tb(typ, raw, string(raw[:len(raw)-1])), true
+			}
+		}
+	})
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+func readBytes(input BufferedByteReader, n int) ([]byte, bool) {
+	capacity := n
+	if capacity > 4096 {
+		capacity = 4096
+	}
+	raw := make([]byte, 0, capacity)
+	for i := 0; i < n; i++ {
+		if input.EOF() {
+			return nil, false
+		}
+		raw = append(raw, input.Read())
+	}
+	return raw, true
+}
+
+// transactionalBytes makes consumer leave the reader untouched when it does not match.
+func transactionalBytes(consumer ByteConsumer) ByteConsumer {
+	return func(input BufferedByteReader) (Token, bool) {
+		mark := input.Mark()
+		tok, valid := consumer(input)
+		if valid {
+			mark.Commit()
+		} else {
+			mark.Rollback()
+		}
+		return tok, valid
+	}
+}
+
+func tb(typ TokenType, raw []byte, data interface{}) Token {
+	return Token{
+		Typ:   typ,
+		Value: string(raw),
+		Data:  data,
+	}
+}
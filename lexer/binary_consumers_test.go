@@ -0,0 +1,178 @@
+package lexer
+
+import (
+	"encoding/binary"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	TokenTypeMagic   TokenType = "MAGIC"
+	TokenTypeInt     TokenType = "INT"
+	TokenTypeBlob    TokenType = "BLOB"
+	TokenTypeCString TokenType = "CSTRING"
+)
+
+func consumeBytes(consumer ByteConsumer, input ...byte) (Token, bool, int) {
+	reader := BytesReader(input)
+	tok, valid := consumer(reader)
+	return tok, valid, reader.Offset()
+}
+
+var _ = Describe("ConsumeUint", func() {
+	It("decodes big endian integers", func() {
+		tok, valid, _ := consumeBytes(ConsumeUint(TokenTypeInt, 4, binary.BigEndian), 0x01, 0x02, 0x03, 0x04, 0x05)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("\x01\x02\x03\x04"))
+		Expect(tok.Data).To(Equal(uint64(0x01020304)))
+	})
+	It("decodes little endian integers", func() {
+		tok, _, _ := consumeBytes(ConsumeUint(TokenTypeInt, 2, binary.LittleEndian), 0x01, 0x02)
+		Expect(tok.Data).To(Equal(uint64(0x0201)))
+	})
+	It("does not match truncated input", func() {
+		_, valid, offset := consumeBytes(ConsumeUint(TokenTypeInt, 8, binary.BigEndian), 1, 2, 3)
+		Expect(valid).To(BeFalse())
+		Expect(offset).To(BeZero())
+	})
+	It("rejects unsupported widths", func() {
+		Expect(func() { ConsumeUint(TokenTypeInt, 3, binary.BigEndian) }).To(Panic())
+	})
+})
+
+var _ = Describe("ConsumeInt", func() {
+	It("sign extends negative integers", func() {
+		tok, _, _ := consumeBytes(ConsumeInt(TokenTypeInt, 2, binary.BigEndian), 0xff, 0xfe)
+		Expect(tok.Data).To(Equal(int64(-2)))
+		tok, _, _ = consumeBytes(ConsumeInt(TokenTypeInt, 1, binary.BigEndian), 0x80)
+		Expect(tok.Data).To(Equal(int64(-128)))
+		tok, _, _ = consumeBytes(ConsumeInt(TokenTypeInt, 8, binary.LittleEndian), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+		Expect(tok.Data).To(Equal(int64(-1)))
+	})
+	It("decodes positive integers", func() {
+		tok, _, _ := consumeBytes(ConsumeInt(TokenTypeInt, 4, binary.LittleEndian), 0x2a, 0, 0, 0)
+		Expect(tok.Data).To(Equal(int64(42)))
+	})
+})
+
+var _ = Describe("ConsumeUvarint", func() {
+	It("decodes LEB128 integers", func() {
+		tok, valid, offset := consumeBytes(ConsumeUvarint(TokenTypeInt), 0xe5, 0x8e, 0x26, 0xff)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(Equal(uint64(624485)))
+		Expect(offset).To(Equal(3))
+	})
+	It("decodes the maximum value", func() {
+		tok, valid, _ := consumeBytes(ConsumeUvarint(TokenTypeInt), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(Equal(^uint64(0)))
+	})
+	It("does not match overflowing encodings", func() {
+		_, valid, _ := consumeBytes(ConsumeUvarint(TokenTypeInt), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02)
+		Expect(valid).To(BeFalse())
+	})
+	It("does not match unterminated encodings", func() {
+		_, valid, offset := consumeBytes(ConsumeUvarint(TokenTypeInt), 0x80, 0x80)
+		Expect(valid).To(BeFalse())
+		Expect(offset).To(BeZero())
+	})
+})
+
+var _ = Describe("ConsumeZigZagVarint", func() {
+	It("decodes protobuf sint values", func() {
+		for encoded, expected := range map[byte]int64{0: 0, 1: -1, 2: 1, 3: -2, 0x7f: -64} {
+			tok, _, _ := consumeBytes(ConsumeZigZagVarint(TokenTypeInt), encoded)
+			Expect(tok.Data).To(Equal(expected))
+		}
+	})
+})
+
+var _ = Describe("ConsumeSLEB128", func() {
+	It("decodes signed LEB128 integers", func() {
+		tok, _, _ := consumeBytes(ConsumeSLEB128(TokenTypeInt), 0xc0, 0xbb, 0x78)
+		Expect(tok.Data).To(Equal(int64(-123456)))
+		tok, _, _ = consumeBytes(ConsumeSLEB128(TokenTypeInt), 0x3f)
+		Expect(tok.Data).To(Equal(int64(63)))
+		tok, _, _ = consumeBytes(ConsumeSLEB128(TokenTypeInt), 0x40)
+		Expect(tok.Data).To(Equal(int64(-64)))
+	})
+	It("decodes the extremes of int64", func() {
+		tok, valid, _ := consumeBytes(ConsumeSLEB128(TokenTypeInt), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(Equal(int64(math.MaxInt64)))
+		tok, valid, _ = consumeBytes(ConsumeSLEB128(TokenTypeInt), 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x7f)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(Equal(int64(math.MinInt64)))
+	})
+	It("does not match overflowing encodings", func() {
+		_, valid, _ := consumeBytes(ConsumeSLEB128(TokenTypeInt), 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01)
+		Expect(valid).To(BeFalse())
+		_, valid, _ = consumeBytes(ConsumeSLEB128(TokenTypeInt), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f)
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeLengthPrefixed", func() {
+	It("consumes the prefixed payload", func() {
+		tok, valid, offset := consumeBytes(ConsumeLengthPrefixed(TokenTypeBlob, ConsumeUvarint(TokenTypeInt)), 3, 'a', 'b', 'c', 'd')
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("\x03abc"))
+		Expect(tok.Data).To(Equal("abc"))
+		Expect(offset).To(Equal(4))
+	})
+	It("does not match truncated payloads", func() {
+		_, valid, offset := consumeBytes(ConsumeLengthPrefixed(TokenTypeBlob, ConsumeUint(TokenTypeInt, 2, binary.BigEndian)), 0, 5, 'a')
+		Expect(valid).To(BeFalse())
+		Expect(offset).To(BeZero())
+	})
+})
+
+var _ = Describe("ConsumeMagic", func() {
+	It("matches the exact bytes", func() {
+		_, valid, _ := consumeBytes(ConsumeMagic(TokenTypeMagic, []byte("\x89PNG")), 0x89, 'P', 'N', 'G', 0)
+		Expect(valid).To(BeTrue())
+		_, valid, _ = consumeBytes(ConsumeMagic(TokenTypeMagic, []byte("\x89PNG")), 0x89, 'P', 'N', 'X')
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeCString", func() {
+	It("consumes up to and including the null byte", func() {
+		tok, valid, offset := consumeBytes(ConsumeCString(TokenTypeCString), 'a', 'b', 0, 'c')
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("ab\x00"))
+		Expect(tok.Data).To(Equal("ab"))
+		Expect(offset).To(Equal(3))
+	})
+	It("does not match unterminated strings", func() {
+		_, valid, _ := consumeBytes(ConsumeCString(TokenTypeCString), 'a', 'b')
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("LexBinary", func() {
+	It("tokenizes a binary message with byte offsets", func() {
+		rv := RecordingVisitor{}
+		message := []byte{'M', 'S', 'G', 0x00, 0x02, 'h', 'i', 0x96, 0x01, 'x', 0}
+		LexBinary(BytesReader(message), rv.visit, TokenTypeEOF, TokenTypeError,
+			ConsumeMagic(TokenTypeMagic, []byte("MSG")),
+			ConsumeLengthPrefixed(TokenTypeBlob, ConsumeUint(TokenTypeInt, 2, binary.BigEndian)),
+			ConsumeUvarint(TokenTypeInt),
+		)
+		Expect(rv.tokens).To(Equal([]Token{
+			{Typ: TokenTypeMagic, Value: "MSG", Offset: 0},
+			{Typ: TokenTypeBlob, Value: "\x00\x02hi", Offset: 3, Data: "hi"},
+			{Typ: TokenTypeInt, Value: "\x96\x01", Offset: 7, Data: uint64(150)},
+			{Typ: TokenTypeInt, Value: "x", Offset: 9, Data: uint64('x')},
+			{Typ: TokenTypeInt, Value: "\x00", Offset: 10, Data: uint64(0)},
+			{Typ: TokenTypeEOF, Offset: 11},
+		}))
+	})
+	It("produces an error token when no consumer matches", func() {
+		rv := RecordingVisitor{}
+		LexBinary(BytesReader([]byte("MSX")), rv.visit, TokenTypeEOF, TokenTypeError, ConsumeMagic(TokenTypeMagic, []byte("MSG")))
+		Expect(rv.tokens).To(Equal([]Token{{Typ: TokenTypeError, Value: "No valid token found", Offset: 0}}))
+	})
+})
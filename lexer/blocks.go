@@ -0,0 +1,173 @@
+package lexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnterminatedError describes a block that reached the end of the input
+// before its terminator. It is reported in the Data of the error token emitted
+// by ConsumeUntil and ConsumeBalanced.
+type UnterminatedError struct {
+	// Offset is the offset the block started at.
+	Offset int
+	// Terminator is the delimiter that was missing.
+	Terminator string
+	// Depth is the number of blocks left open; always 1 for ConsumeUntil.
+	Depth int
+}
+
+func (e *UnterminatedError) Error() string {
+	return fmt.Sprintf("unterminated block starting at offset %d: missing %q (depth %d)", e.Offset, e.Terminator, e.Depth)
+}
+
+// UntilOptions adjust ConsumeUntil.
+type UntilOptions struct {
+	// Escape, if not 0, makes the rune following it part of the value even if
+	// it starts the terminator. The escape rune itself is kept as well.
+	Escape rune
+	// IncludeTerminator adds the terminator to the value. Otherwise it is left
+	// in the input.
+	IncludeTerminator bool
+	// Unterminated, if set, is the type of the token emitted when the input ends
+	// before the terminator, with an *UnterminatedError as Data. Otherwise the
+	// consumer does not match in that case.
+	Unterminated TokenType
+}
+
+// ConsumeUntil consumes everything up to the next occurrence of terminator,
+// which must not be empty. It does not match if the value would be empty.
+func ConsumeUntil(typ TokenType, terminator string, options UntilOptions) TokenConsumer {
+	if terminator == "" {
+		panic("lexer: ConsumeUntil requires a terminator")
+	}
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		start := input.Offset()
+		value := newValueBuilder(input)
+		for {
+			if input.EOF() {
+				if value.String() == "" {
+					return Token{}, false
+				}
+				return unterminated(options.Unterminated, value.String(), start, terminator, 1)
+			}
+			if options.IncludeTerminator {
+				if consumeLiteral(input, terminator, &value) {
+					return t(typ, value.String()), true
+				}
+			} else if lookingAt(input, terminator) {
+				if value.String() == "" {
+					return Token{}, false
+				}
+				return t(typ, value.String()), true
+			}
+			r := input.Read()
+			value.WriteRune(r)
+			if r == options.Escape && options.Escape != 0 && !input.EOF() {
+				value.WriteRune(input.Read())
+			}
+		}
+	})
+}
+
+// BalancedOptions adjust ConsumeBalanced.
+type BalancedOptions struct {
+	// Quotes lists the runes delimiting strings, inside of which delimiters are
+	// not counted.
+	Quotes string
+	// Escape, if not 0, makes the rune following it inside a string part of the
+	// string even if it is a quote.
+	Escape rune
+	// Unterminated, if set, is the type of the token emitted when the input ends
+	// before the block is closed, with an *UnterminatedError as Data. Otherwise
+	// the consumer does not match in that case.
+	Unterminated TokenType
+}
+
+// ConsumeBalanced consumes a block starting with open and ending with the
+// matching close, including both, counting nested blocks. Neither open nor
+// close may be empty.
+func ConsumeBalanced(typ TokenType, open, close string, options BalancedOptions) TokenConsumer {
+	if open == "" || close == "" {
+		panic("lexer: ConsumeBalanced requires delimiters")
+	}
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		start := input.Offset()
+		value := newValueBuilder(input)
+		if !consumeLiteral(input, open, &value) {
+			return Token{}, false
+		}
+		depth := 1
+		for depth > 0 {
+			switch {
+			case input.EOF():
+				return unterminated(options.Unterminated, value.String(), start, close, depth)
+			case consumeLiteral(input, close, &value):
+				depth--
+			case consumeLiteral(input, open, &value):
+				depth++
+			case strings.ContainsRune(options.Quotes, input.Peek()):
+				if !consumeQuoted(input, &value, options.Escape) {
+					return unterminated(options.Unterminated, value.String(), start, close, depth)
+				}
+			default:
+				value.WriteRune(input.Read())
+			}
+		}
+		return t(typ, value.String()), true
+	})
+}
+
+// consumeQuoted consumes a string delimited by the next rune and reports
+// whether it has been closed.
+func consumeQuoted(input BufferedRuneReader, value *valueBuilder, escape rune) bool {
+	quote := input.Read()
+	value.WriteRune(quote)
+	for !input.EOF() {
+		r := input.Read()
+		value.WriteRune(r)
+		switch {
+		case r == quote:
+			return true
+		case r == escape && escape != 0 && !input.EOF():
+			value.WriteRune(input.Read())
+		}
+	}
+	return false
+}
+
+// consumeLiteral consumes text if the input continues with it.
+func consumeLiteral(input BufferedRuneReader, text string, value *valueBuilder) bool {
+	if !lookingAt(input, text) {
+		return false
+	}
+	for range text {
+		value.WriteRune(input.Read())
+	}
+	return true
+}
+
+// lookingAt reports whether the input continues with text, without consuming it.
+func lookingAt(input BufferedRuneReader, text string) bool {
+	mark := input.Mark()
+	defer mark.Rollback()
+	for _, expected := range text {
+		if input.EOF() || input.Read() != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func unterminated(typ TokenType, value string, offset int, terminator string, depth int) (Token, bool) {
+	if typ == "" {
+		return Token{}, false
+	}
+	tok := t(typ, value)
+	tok.Data = &UnterminatedError{
+		Offset:     offset,
+		Terminator: terminator,
+		Depth:      depth,
+	}
+	return tok, true
+}
@@ -0,0 +1,133 @@
+package lexer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	TokenTypeBlock        TokenType = "BLOCK"
+	TokenTypeUnterminated TokenType = "UNTERMINATED"
+)
+
+var _ = Describe("ConsumeUntil", func() {
+	It("consumes up to the terminator", func() {
+		reader := StringReader("a * b */ c")
+		tok, valid := ConsumeUntil(TokenTypeComment, "*/", UntilOptions{})(reader)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("a * b "))
+		Expect(reader.Offset()).To(Equal(6))
+	})
+	It("includes the terminator if requested", func() {
+		reader := StringReader("a */ c")
+		tok, _ := ConsumeUntil(TokenTypeComment, "*/", UntilOptions{IncludeTerminator: true})(reader)
+		Expect(tok.Value).To(Equal("a */"))
+		Expect(reader.Offset()).To(Equal(4))
+	})
+	It("does not match an empty value", func() {
+		_, valid := ConsumeUntil(TokenTypeComment, "*/", UntilOptions{})(StringReader("*/"))
+		Expect(valid).To(BeFalse())
+		_, valid = ConsumeUntil(TokenTypeComment, "*/", UntilOptions{Unterminated: TokenTypeUnterminated})(StringReader(""))
+		Expect(valid).To(BeFalse())
+	})
+	It("rejects an empty terminator", func() {
+		Expect(func() { ConsumeUntil(TokenTypeComment, "", UntilOptions{}) }).To(Panic())
+	})
+	It("lexes on when standing at the terminator", func() {
+		rv := RecordingVisitor{}
+		LexStatic(StringReader("}"), rv.visit, TokenTypeEOF, TokenTypeError, ConsumeUntil(TokenTypeString, "}", UntilOptions{}))
+		Expect(rv.tokens).To(HaveLen(1))
+		Expect(rv.tokens[0].Typ).To(Equal(TokenTypeError))
+	})
+	It("skips escaped terminators", func() {
+		tok, _ := ConsumeUntil(TokenTypeString, "\"", UntilOptions{Escape: '\\'})(StringReader(`say \"hi\"" rest`))
+		Expect(tok.Value).To(Equal(`say \"hi\"`))
+	})
+	It("does not match unterminated input by default", func() {
+		reader := StringReader("a * b")
+		_, valid := ConsumeUntil(TokenTypeComment, "*/", UntilOptions{})(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+	})
+	It("reports unterminated input as structured error", func() {
+		reader := StringReader("a * b")
+		reader.Read()
+		tok, valid := ConsumeUntil(TokenTypeComment, "*/", UntilOptions{Unterminated: TokenTypeUnterminated})(reader)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Typ).To(Equal(TokenTypeUnterminated))
+		Expect(tok.Value).To(Equal(" * b"))
+		Expect(tok.Data).To(Equal(&UnterminatedError{Offset: 1, Terminator: "*/", Depth: 1}))
+	})
+})
+
+var _ = Describe("ConsumeBalanced", func() {
+	options := BalancedOptions{Quotes: "\"'", Escape: '\\', Unterminated: TokenTypeUnterminated}
+	It("rejects empty delimiters", func() {
+		Expect(func() { ConsumeBalanced(TokenTypeBlock, "", "}", options) }).To(Panic())
+		Expect(func() { ConsumeBalanced(TokenTypeBlock, "{", "", options) }).To(Panic())
+	})
+	It("consumes a nested block", func() {
+		reader := StringReader("{ a { b } { c { d } } } e }")
+		tok, valid := ConsumeBalanced(TokenTypeBlock, "{", "}", options)(reader)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("{ a { b } { c { d } } }"))
+	})
+	It("supports multi-rune delimiters", func() {
+		tok, valid := ConsumeBalanced(TokenTypeBlock, "{{", "}}", options)(StringReader("{{ a {{ b }} } }} c"))
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("{{ a {{ b }} } }}"))
+	})
+	It("ignores delimiters inside strings", func() {
+		tok, valid := ConsumeBalanced(TokenTypeBlock, "{", "}", options)(StringReader(`{ print("}", '{', "\"}") } x`))
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal(`{ print("}", '{', "\"}") }`))
+	})
+	It("does not match without opening delimiter", func() {
+		reader := StringReader("a { }")
+		_, valid := ConsumeBalanced(TokenTypeBlock, "{", "}", options)(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+	})
+	It("reports unclosed blocks with their depth", func() {
+		tok, valid := ConsumeBalanced(TokenTypeBlock, "{", "}", options)(StringReader("{ a { b }  { c"))
+		Expect(valid).To(BeTrue())
+		Expect(tok.Typ).To(Equal(TokenTypeUnterminated))
+		Expect(tok.Data).To(Equal(&UnterminatedError{Offset: 0, Terminator: "}", Depth: 2}))
+		Expect(tok.Data.(error).Error()).To(Equal(`unterminated block starting at offset 0: missing "}" (depth 2)`))
+	})
+	It("reports unclosed strings inside blocks", func() {
+		tok, _ := ConsumeBalanced(TokenTypeBlock, "{", "}", options)(StringReader(`{ "} `))
+		Expect(tok.Typ).To(Equal(TokenTypeUnterminated))
+		Expect(tok.Value).To(Equal(`{ "} `))
+	})
+	It("does not match unclosed blocks without error type", func() {
+		reader := StringReader("{ a")
+		_, valid := ConsumeBalanced(TokenTypeBlock, "{", "}", BalancedOptions{})(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+	})
+	It("embeds opaque blocks in a token stream", func() {
+		rv := RecordingVisitor{}
+		reader := SourceStringReader("x {a}{}")
+		LexStatic(reader, rv.visit, TokenTypeEOF, TokenTypeError,
+			ConsumeBalanced(TokenTypeBlock, "{", "}", options),
+			ConsumeUntil(TokenTypeString, "{", UntilOptions{Unterminated: TokenTypeString}),
+		)
+		Expect(rv.tokens).To(Equal([]Token{
+			{Typ: TokenTypeString, Value: "x ", Offset: 0},
+			{Typ: TokenTypeBlock, Value: "{a}", Offset: 2},
+			{Typ: TokenTypeBlock, Value: "{}", Offset: 5},
+			{Typ: TokenTypeEOF, Offset: 7},
+		}))
+	})
+	It("stops at blocks left open", func() {
+		rv := RecordingVisitor{}
+		LexStatic(StringReader("x {a"), rv.visit, TokenTypeEOF, TokenTypeError,
+			ConsumeBalanced(TokenTypeBlock, "{", "}", BalancedOptions{}),
+			ConsumeUntil(TokenTypeString, "{", UntilOptions{}),
+		)
+		Expect(rv.tokens).To(HaveLen(2))
+		Expect(rv.tokens[0].Value).To(Equal("x "))
+		Expect(rv.tokens[1].Typ).To(Equal(TokenTypeError))
+	})
+})
@@ -0,0 +1,66 @@
+package lexer
+
+// BufferedByteReader is the byte oriented counterpart of BufferedRuneReader for
+// binary formats. Offsets are counted in bytes.
+type BufferedByteReader interface {
+	Mark() Mark
+	Offset() int
+	// Read returns the next byte, or 0 at the end of the input.
+	Read() byte
+	Peek() byte
+	Rewind()
+	Error() error
+	EOF() bool
+}
+
+type bytesReader struct {
+	input  []byte
+	offset int
+	marks  *MarkStack
+}
+
+func (s *bytesReader) Mark() Mark {
+	return s.marks.Push(s.offset, s.offset)
+}
+
+func (s *bytesReader) Offset() int {
+	return s.offset
+}
+
+func (s *bytesReader) Read() byte {
+	if !s.EOF() {
+		b := s.input[s.offset]
+		s.offset++
+		return b
+	}
+	return 0
+}
+
+func (s *bytesReader) Peek() byte {
+	if !s.EOF() {
+		return s.input[s.offset]
+	}
+	return 0
+}
+
+func (s *bytesReader) Rewind() {
+	s.marks.Rewind()
+}
+
+func (s *bytesReader) Error() error {
+	return nil
+}
+
+func (s *bytesReader) EOF() bool {
+	return s.offset >= len(s.input)
+}
+
+func BytesReader(input []byte) BufferedByteReader {
+	reader := &bytesReader{
+		input: input,
+	}
+	reader.marks = NewMarkStack(func(offset, _ int) {
+		reader.offset = offset
+	})
+	return reader
+}
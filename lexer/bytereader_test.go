@@ -0,0 +1,37 @@
+package lexer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("bytesReader", func() {
+	It("reports EOF for empty input", func() {
+		Expect(BytesReader(nil).EOF()).To(BeTrue())
+	})
+	It("returns 0 on peek and read at EOF", func() {
+		s := BytesReader(nil)
+		Expect(s.Peek()).To(BeZero())
+		Expect(s.Read()).To(BeZero())
+	})
+	It("reads bytes and counts byte offsets", func() {
+		s := BytesReader([]byte{0xc3, 0xbc, 0x01})
+		Expect(s.Peek()).To(Equal(byte(0xc3)))
+		Expect(s.Read()).To(Equal(byte(0xc3)))
+		Expect(s.Read()).To(Equal(byte(0xbc)))
+		Expect(s.Offset()).To(Equal(2))
+		Expect(s.Error()).To(BeNil())
+	})
+	It("rolls back and rewinds marks", func() {
+		s := BytesReader([]byte{1, 2, 3, 4})
+		s.Read()
+		mark := s.Mark()
+		s.Read()
+		s.Mark()
+		s.Read()
+		s.Rewind()
+		Expect(s.Offset()).To(Equal(2))
+		mark.Rollback()
+		Expect(s.Offset()).To(Equal(1))
+	})
+})
@@ -7,7 +7,7 @@ import (
 )
 
 func ConsumeSingleRune(typ TokenType, expected ...rune) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
 		r := input.Read()
 		for _, ex := range expected {
 			if r == ex {
@@ -15,12 +15,12 @@ func ConsumeSingleRune(typ TokenType, expected ...rune) TokenConsumer {
 			}
 		}
 		return Token{}, false
-	}
+	})
 }
 
 func ConsumeCharacterClass(typ TokenType, classes ...*unicode.RangeTable) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
-		var value strings.Builder
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		value := newValueBuilder(input)
 		for {
 			if input.EOF() {
 				break
@@ -36,12 +36,12 @@ func ConsumeCharacterClass(typ TokenType, classes ...*unicode.RangeTable) TokenC
 			return t(typ, value.String()), true
 		}
 		return Token{}, false
-	}
+	})
 }
 
 func ConsumeRunes(typ TokenType, runeString string) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
-		var value strings.Builder
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		value := newValueBuilder(input)
 		for {
 			if input.EOF() {
 				break
@@ -57,11 +57,11 @@ func ConsumeRunes(typ TokenType, runeString string) TokenConsumer {
 			return t(typ, value.String()), true
 		}
 		return Token{}, false
-	}
+	})
 }
 
 func ConsumeRegexpValidated(re *regexp.Regexp, consumer TokenConsumer) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
 		tok, valid := consumer(input)
 		if !valid {
 			return tok, valid
@@ -70,12 +70,12 @@ func ConsumeRegexpValidated(re *regexp.Regexp, consumer TokenConsumer) TokenCons
 			return tok, true
 		}
 		return Token{}, false
-	}
+	})
 }
 
 func ConsumeText(typ TokenType, text string) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
-		var value strings.Builder
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		value := newValueBuilder(input)
 		for _, expected := range []rune(text) {
 			if input.Peek() == expected {
 				value.WriteRune(input.Read())
@@ -84,12 +84,12 @@ func ConsumeText(typ TokenType, text string) TokenConsumer {
 			}
 		}
 		return t(typ, value.String()), true
-	}
+	})
 }
 
 func ConsumeRegex(typ TokenType, re *regexp.Regexp) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
-		var value strings.Builder
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		value := newValueBuilder(input)
 		for {
 			if input.EOF() {
 				break
@@ -100,14 +100,21 @@ func ConsumeRegex(typ TokenType, re *regexp.Regexp) TokenConsumer {
 			}
 		}
 		return Token{}, false
-	}
+	})
 }
 
 func ConsumeString(typ TokenType) TokenConsumer {
-	return func(input BufferedRuneReader) (Token, bool) {
+	return ConsumeDelimitedString(typ, "\"'")
+}
+
+// ConsumeDelimitedString consumes a string starting and ending with the same
+// rune out of delimiters. A delimiter preceded by a backslash does not end the
+// string and is kept without the backslash.
+func ConsumeDelimitedString(typ TokenType, delimiters string) TokenConsumer {
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
 		var value strings.Builder
 		delimiter := input.Read()
-		if !(delimiter == '"' || delimiter == '\'') {
+		if strings.IndexRune(delimiters, delimiter) == -1 {
 			return Token{}, false
 		}
 		value.WriteRune(delimiter)
@@ -135,7 +142,43 @@ func ConsumeString(typ TokenType) TokenConsumer {
 			}
 			value.WriteRune(r)
 		}
+	})
+}
+
+// valueBuilder accumulates the value of a token whose value equals its source
+// text. On a SourceReader the value is sliced out of the source when done,
+// otherwise the runes are copied into a strings.Builder.
+type valueBuilder struct {
+	source  SourceReader
+	start   int
+	length  int
+	builder strings.Builder
+}
+
+func newValueBuilder(input BufferedRuneReader) valueBuilder {
+	source, _ := input.(SourceReader)
+	return valueBuilder{
+		source: source,
+		start:  input.Offset(),
+	}
+}
+
+func (s *valueBuilder) WriteRune(r rune) {
+	s.length++
+	if s.source == nil {
+		s.builder.WriteRune(r)
+	}
+}
+
+func (s *valueBuilder) Len() int {
+	return s.length
+}
+
+func (s *valueBuilder) String() string {
+	if s.source != nil {
+		return s.source.Since(s.start)
 	}
+	return s.builder.String()
 }
 
 func t(typ TokenType, value string) Token {
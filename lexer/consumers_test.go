@@ -155,3 +155,18 @@ var _ = Describe("ConsumeString", func() {
 		Expect(valid).To(BeFalse())
 	})
 })
+
+var _ = Describe("ConsumeDelimitedString", func() {
+	It("returns a valid token on successful tokenization", func() {
+		reader := StringReader("`ab\\`c` def")
+		tok, valid := ConsumeDelimitedString(TokenTypeString, "`")(reader)
+		Expect(tok.Typ).To(Equal(TokenTypeString))
+		Expect(tok.Value).To(Equal("`ab`c`"))
+		Expect(valid).To(BeTrue())
+	})
+	It("returns an invalid token on other delimiters", func() {
+		reader := StringReader("'abc'")
+		_, valid := ConsumeDelimitedString(TokenTypeString, "`")(reader)
+		Expect(valid).To(BeFalse())
+	})
+})
@@ -0,0 +1,175 @@
+// Package dfa compiles an ordered set of regular expressions into a single
+// deterministic finite automaton that identifies the longest match and the rule
+// it belongs to in one pass over the input.
+package dfa
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rule is one pattern, in regexp/syntax Perl syntax, that the automaton
+// recognizes. When several rules match the same longest input, the one with the
+// highest Priority wins, and among equal priorities the earliest rule.
+type Rule struct {
+	Pattern  string
+	Priority int
+}
+
+// Transition moves to state Next on every rune in [Lo, Hi].
+type Transition struct {
+	Lo   rune
+	Hi   rune
+	Next int
+}
+
+// State is a DFA state. Accept is the index of the rule matched when the
+// automaton stops in this state, or -1. Transitions are sorted and disjoint.
+type State struct {
+	Accept      int
+	Transitions []Transition
+}
+
+// DFA is a compiled automaton. State 0 is the start state.
+type DFA struct {
+	States []State
+}
+
+// Compile builds a DFA recognizing all rules. Rules that match the empty string
+// are rejected as they would make a lexer loop without consuming input.
+func Compile(rules ...Rule) (*DFA, error) {
+	n := &nfa{}
+	start := n.newState()
+	for i, rule := range rules {
+		frag, err := n.compilePattern(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%q): %w", i, rule.Pattern, err)
+		}
+		accept := n.newState()
+		n.states[accept].accept = i
+		n.patch(frag.out, accept)
+		n.addEpsilon(start, frag.start)
+	}
+	d := determinize(n, start, rules)
+	if d.States[0].Accept >= 0 {
+		return nil, fmt.Errorf("rule %d (%q) matches the empty string", d.States[0].Accept, rules[d.States[0].Accept].Pattern)
+	}
+	return d, nil
+}
+
+// Step returns the state reached from state on r, or -1 if the automaton dies.
+func (s *DFA) Step(state int, r rune) int {
+	transitions := s.States[state].Transitions
+	i := sort.Search(len(transitions), func(i int) bool {
+		return transitions[i].Hi >= r
+	})
+	if i < len(transitions) && transitions[i].Lo <= r {
+		return transitions[i].Next
+	}
+	return -1
+}
+
+// Match returns the rule index and length in runes of the longest prefix of
+// input matched by any rule, or -1 and 0 if no rule matches.
+func (s *DFA) Match(input []rune) (rule, length int) {
+	rule = -1
+	state := 0
+	for i, r := range input {
+		state = s.Step(state, r)
+		if state < 0 {
+			break
+		}
+		if accept := s.States[state].Accept; accept >= 0 {
+			rule, length = accept, i+1
+		}
+	}
+	return rule, length
+}
+
+func determinize(n *nfa, start int, rules []Rule) *DFA {
+	d := &DFA{}
+	index := make(map[string]int)
+	var queue [][]int
+	add := func(set []int) int {
+		key := setKey(set)
+		if id, ok := index[key]; ok {
+			return id
+		}
+		id := len(d.States)
+		index[key] = id
+		d.States = append(d.States, State{Accept: acceptOf(n, set, rules)})
+		queue = append(queue, set)
+		return id
+	}
+	add(n.closure([]int{start}))
+	for id := 0; id < len(queue); id++ {
+		d.States[id].Transitions = transitionsOf(n, queue[id], add)
+	}
+	return d
+}
+
+func acceptOf(n *nfa, set []int, rules []Rule) int {
+	accept := -1
+	for _, state := range set {
+		rule := n.states[state].accept
+		if rule < 0 {
+			continue
+		}
+		if accept < 0 || rules[rule].Priority > rules[accept].Priority ||
+			(rules[rule].Priority == rules[accept].Priority && rule < accept) {
+			accept = rule
+		}
+	}
+	return accept
+}
+
+// transitionsOf splits the rune ranges leaving set into disjoint intervals and
+// computes the successor set for each of them.
+func transitionsOf(n *nfa, set []int, add func([]int) int) []Transition {
+	var bounds []rune
+	for _, state := range set {
+		for _, edge := range n.states[state].edges {
+			bounds = append(bounds, edge.lo, edge.hi+1)
+		}
+	}
+	if len(bounds) == 0 {
+		return nil
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	var transitions []Transition
+	for i := 0; i+1 < len(bounds); i++ {
+		lo, hi := bounds[i], bounds[i+1]-1
+		if lo > hi {
+			continue
+		}
+		var targets []int
+		for _, state := range set {
+			for _, edge := range n.states[state].edges {
+				if edge.lo <= lo && hi <= edge.hi {
+					targets = append(targets, edge.to)
+				}
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		next := add(n.closure(targets))
+		if last := len(transitions) - 1; last >= 0 && transitions[last].Next == next && transitions[last].Hi+1 == lo {
+			transitions[last].Hi = hi
+			continue
+		}
+		transitions = append(transitions, Transition{Lo: lo, Hi: hi, Next: next})
+	}
+	return transitions
+}
+
+func setKey(set []int) string {
+	var b strings.Builder
+	for _, state := range set {
+		b.WriteString(strconv.Itoa(state))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
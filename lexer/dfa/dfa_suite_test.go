@@ -0,0 +1,13 @@
+package dfa
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestDfa(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DFA Suite")
+}
@@ -0,0 +1,82 @@
+package dfa
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func match(d *DFA, input string) (int, int) {
+	return d.Match([]rune(input))
+}
+
+var _ = Describe("Compile", func() {
+	It("matches a literal", func() {
+		d, err := Compile(Rule{Pattern: "abc"})
+		Expect(err).ToNot(HaveOccurred())
+		rule, length := match(d, "abcd")
+		Expect(rule).To(Equal(0))
+		Expect(length).To(Equal(3))
+	})
+	It("reports no match", func() {
+		d, _ := Compile(Rule{Pattern: "abc"})
+		rule, length := match(d, "abd")
+		Expect(rule).To(Equal(-1))
+		Expect(length).To(Equal(0))
+	})
+	It("prefers the longest match over rule order", func() {
+		d, _ := Compile(Rule{Pattern: "if"}, Rule{Pattern: "[a-z]+"})
+		rule, length := match(d, "iffy ")
+		Expect(rule).To(Equal(1))
+		Expect(length).To(Equal(4))
+	})
+	It("breaks ties of equal length by rule order", func() {
+		d, _ := Compile(Rule{Pattern: "if"}, Rule{Pattern: "[a-z]+"})
+		rule, length := match(d, "if ")
+		Expect(rule).To(Equal(0))
+		Expect(length).To(Equal(2))
+	})
+	It("breaks ties of equal length by priority first", func() {
+		d, _ := Compile(Rule{Pattern: "if"}, Rule{Pattern: "[a-z]+", Priority: 1})
+		rule, _ := match(d, "if ")
+		Expect(rule).To(Equal(1))
+	})
+	It("supports repetition, alternation and classes", func() {
+		d, err := Compile(Rule{Pattern: `-?(0|[1-9]\d*)(\.\d+)?`}, Rule{Pattern: `\s+`})
+		Expect(err).ToNot(HaveOccurred())
+		rule, length := match(d, "-12.50)")
+		Expect(rule).To(Equal(0))
+		Expect(length).To(Equal(6))
+		rule, length = match(d, " \t\nx")
+		Expect(rule).To(Equal(1))
+		Expect(length).To(Equal(3))
+	})
+	It("supports bounded repetition", func() {
+		d, _ := Compile(Rule{Pattern: `a{2,3}`})
+		_, length := match(d, "aaaa")
+		Expect(length).To(Equal(3))
+		rule, _ := match(d, "a")
+		Expect(rule).To(Equal(-1))
+	})
+	It("supports case folding", func() {
+		d, _ := Compile(Rule{Pattern: `(?i)select`})
+		_, length := match(d, "SeLeCt")
+		Expect(length).To(Equal(6))
+	})
+	It("matches non-ASCII runes", func() {
+		d, _ := Compile(Rule{Pattern: `\p{Greek}+`})
+		_, length := match(d, "λόγος!")
+		Expect(length).To(Equal(5))
+	})
+	It("rejects rules matching the empty string", func() {
+		_, err := Compile(Rule{Pattern: "a*"})
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects invalid patterns", func() {
+		_, err := Compile(Rule{Pattern: "a("})
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects anchors", func() {
+		_, err := Compile(Rule{Pattern: `a\b`})
+		Expect(err).To(HaveOccurred())
+	})
+})
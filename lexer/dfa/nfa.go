@@ -0,0 +1,180 @@
+package dfa
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+)
+
+type edge struct {
+	lo rune
+	hi rune
+	to int
+}
+
+type nfaState struct {
+	accept  int
+	edges   []edge
+	epsilon []int
+}
+
+// nfa is a Thompson construction over rune ranges.
+type nfa struct {
+	states []nfaState
+}
+
+// fragment is a partially built automaton. out lists the dangling epsilon
+// exits that have to be patched to the following state.
+type fragment struct {
+	start int
+	out   []int
+}
+
+func (s *nfa) newState() int {
+	s.states = append(s.states, nfaState{accept: -1})
+	return len(s.states) - 1
+}
+
+func (s *nfa) addEpsilon(from, to int) {
+	s.states[from].epsilon = append(s.states[from].epsilon, to)
+}
+
+func (s *nfa) patch(out []int, to int) {
+	for _, state := range out {
+		s.addEpsilon(state, to)
+	}
+}
+
+// closure returns the sorted epsilon closure of states.
+func (s *nfa) closure(states []int) []int {
+	seen := make(map[int]bool)
+	stack := append([]int(nil), states...)
+	for len(stack) > 0 {
+		state := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[state] {
+			continue
+		}
+		seen[state] = true
+		stack = append(stack, s.states[state].epsilon...)
+	}
+	set := make([]int, 0, len(seen))
+	for state := range seen {
+		set = append(set, state)
+	}
+	sort.Ints(set)
+	return set
+}
+
+func (s *nfa) compilePattern(pattern string) (fragment, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fragment{}, err
+	}
+	return s.compile(re.Simplify())
+}
+
+func (s *nfa) compile(re *syntax.Regexp) (fragment, error) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return s.empty(), nil
+	case syntax.OpNoMatch:
+		return fragment{start: s.newState()}, nil
+	case syntax.OpLiteral:
+		frags := make([]fragment, 0, len(re.Rune))
+		for _, r := range re.Rune {
+			ranges := []rune{r, r}
+			if re.Flags&syntax.FoldCase != 0 {
+				ranges = foldRanges(r)
+			}
+			frags = append(frags, s.ranges(ranges))
+		}
+		return s.concat(frags), nil
+	case syntax.OpCharClass:
+		return s.ranges(re.Rune), nil
+	case syntax.OpAnyCharNotNL:
+		return s.ranges([]rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}), nil
+	case syntax.OpAnyChar:
+		return s.ranges([]rune{0, unicode.MaxRune}), nil
+	case syntax.OpCapture:
+		return s.compile(re.Sub[0])
+	case syntax.OpConcat, syntax.OpAlternate:
+		frags := make([]fragment, 0, len(re.Sub))
+		for _, sub := range re.Sub {
+			frag, err := s.compile(sub)
+			if err != nil {
+				return fragment{}, err
+			}
+			frags = append(frags, frag)
+		}
+		if re.Op == syntax.OpConcat {
+			return s.concat(frags), nil
+		}
+		return s.alternate(frags), nil
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		frag, err := s.compile(re.Sub[0])
+		if err != nil {
+			return fragment{}, err
+		}
+		return s.repeat(re.Op, frag), nil
+	}
+	return fragment{}, fmt.Errorf("unsupported regular expression construct %q", re.String())
+}
+
+func (s *nfa) empty() fragment {
+	state := s.newState()
+	return fragment{start: state, out: []int{state}}
+}
+
+func (s *nfa) ranges(ranges []rune) fragment {
+	from, to := s.newState(), s.newState()
+	for i := 0; i+1 < len(ranges); i += 2 {
+		s.states[from].edges = append(s.states[from].edges, edge{lo: ranges[i], hi: ranges[i+1], to: to})
+	}
+	return fragment{start: from, out: []int{to}}
+}
+
+func (s *nfa) concat(frags []fragment) fragment {
+	if len(frags) == 0 {
+		return s.empty()
+	}
+	for i := 1; i < len(frags); i++ {
+		s.patch(frags[i-1].out, frags[i].start)
+	}
+	return fragment{start: frags[0].start, out: frags[len(frags)-1].out}
+}
+
+func (s *nfa) alternate(frags []fragment) fragment {
+	start := s.newState()
+	var out []int
+	for _, frag := range frags {
+		s.addEpsilon(start, frag.start)
+		out = append(out, frag.out...)
+	}
+	return fragment{start: start, out: out}
+}
+
+func (s *nfa) repeat(op syntax.Op, frag fragment) fragment {
+	entry := s.newState()
+	s.addEpsilon(entry, frag.start)
+	switch op {
+	case syntax.OpStar:
+		s.patch(frag.out, entry)
+		return fragment{start: entry, out: []int{entry}}
+	case syntax.OpPlus:
+		s.patch(frag.out, entry)
+		return fragment{start: frag.start, out: []int{entry}}
+	default:
+		return fragment{start: entry, out: append([]int{entry}, frag.out...)}
+	}
+}
+
+// foldRanges returns the single-rune ranges of the case folding orbit of r.
+func foldRanges(r rune) []rune {
+	ranges := []rune{r, r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		ranges = append(ranges, f, f)
+	}
+	return ranges
+}
@@ -0,0 +1,88 @@
+package lexer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RuneSet reports whether a rune is a member of a set.
+type RuneSet func(r rune) bool
+
+// RunesOf returns a RuneSet containing exactly the runes in runeString.
+func RunesOf(runeString string) RuneSet {
+	return func(r rune) bool {
+		return strings.IndexRune(runeString, r) != -1
+	}
+}
+
+// ClassOf returns a RuneSet containing all runes in the given classes.
+func ClassOf(classes ...*unicode.RangeTable) RuneSet {
+	return func(r rune) bool {
+		return unicode.In(r, classes...)
+	}
+}
+
+// Candidate couples a TokenConsumer with the set of runes it can start with. A
+// nil First set means the consumer may start with any rune.
+type Candidate struct {
+	Consumer TokenConsumer
+	First    RuneSet
+}
+
+// StartsWith declares that consumer can only match when the next rune is in first.
+func StartsWith(first RuneSet, consumer TokenConsumer) Candidate {
+	return Candidate{
+		Consumer: consumer,
+		First:    first,
+	}
+}
+
+// Anywhere declares a consumer that is tried regardless of the next rune.
+func Anywhere(consumer TokenConsumer) Candidate {
+	return Candidate{
+		Consumer: consumer,
+	}
+}
+
+// DispatchTable precomputes, for every ASCII rune, the ordered list of consumers
+// that can start with it. Runes outside ASCII are matched against the declared
+// First sets on demand.
+type DispatchTable struct {
+	candidates []Candidate
+	ascii      [unicode.MaxASCII + 1][]TokenConsumer
+}
+
+func NewDispatchTable(candidates ...Candidate) *DispatchTable {
+	table := &DispatchTable{
+		candidates: candidates,
+	}
+	for r := range table.ascii {
+		table.ascii[r] = table.filter(rune(r))
+	}
+	return table
+}
+
+// Candidates returns the consumers to try, in declaration order, when the next
+// rune is r.
+func (s *DispatchTable) Candidates(r rune) []TokenConsumer {
+	if r >= 0 && r <= unicode.MaxASCII {
+		return s.ascii[r]
+	}
+	return s.filter(r)
+}
+
+func (s *DispatchTable) filter(r rune) []TokenConsumer {
+	consumers := make([]TokenConsumer, 0, len(s.candidates))
+	for _, candidate := range s.candidates {
+		if candidate.First == nil || candidate.First(r) {
+			consumers = append(consumers, candidate.Consumer)
+		}
+	}
+	return consumers
+}
+
+// LexDispatched scans the input like LexStatic but only tries the consumers the
+// table lists for the next rune.
+func LexDispatched(input BufferedRuneReader, visitor Visitor, eofToken, errorToken TokenType, table *DispatchTable) {
+	lex(input, visitor, eofToken, errorToken, table.Candidates)
+}
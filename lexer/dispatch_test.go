@@ -0,0 +1,52 @@
+package lexer
+
+import (
+	"unicode"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var SexpDispatchTable = NewDispatchTable(
+	StartsWith(RunesOf("("), ConsumeSingleRune(TokenTypeStart, '(')),
+	StartsWith(RunesOf(")"), ConsumeSingleRune(TokenTypeEnd, ')')),
+	StartsWith(RunesOf(sexpSymbolRunes), ConsumeRunes(TokenTypeSymbol, sexpSymbolRunes)),
+	StartsWith(ClassOf(unicode.White_Space), ConsumeCharacterClass(TokenTypeWhitespace, unicode.White_Space)),
+	StartsWith(RunesOf("\"'"), ConsumeString(TokenTypeString)),
+)
+
+var _ = Describe("DispatchTable", func() {
+	It("lists only the consumers declared for a rune", func() {
+		Expect(SexpDispatchTable.Candidates('(')).To(HaveLen(1))
+		Expect(SexpDispatchTable.Candidates('a')).To(HaveLen(1))
+		Expect(SexpDispatchTable.Candidates('!')).To(BeEmpty())
+	})
+	It("matches non-ASCII runes against the declared sets", func() {
+		Expect(SexpDispatchTable.Candidates('\u2003')).To(HaveLen(1))
+		Expect(SexpDispatchTable.Candidates('ä')).To(BeEmpty())
+	})
+	It("tries undeclared consumers at every position", func() {
+		table := NewDispatchTable(
+			StartsWith(RunesOf("("), ConsumeSingleRune(TokenTypeStart, '(')),
+			Anywhere(ConsumeSingleRune(TokenTypeEnd, ')')),
+		)
+		Expect(table.Candidates('(')).To(HaveLen(2))
+		Expect(table.Candidates('x')).To(HaveLen(1))
+		Expect(table.Candidates('ö')).To(HaveLen(1))
+	})
+	It("produces the same tokens as LexStatic", func() {
+		input := "(define (f x) \"a \\\"b\\\"\" ( g  'c' ))"
+		static, dispatched := RecordingVisitor{}, RecordingVisitor{}
+		LexStatic(StringReader(input), static.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		LexDispatched(StringReader(input), dispatched.visit, TokenTypeEOF, TokenTypeError, SexpDispatchTable)
+		Expect(dispatched.tokens).To(Equal(static.tokens))
+	})
+	It("produces an error token when no consumer is declared for a rune", func() {
+		rv := RecordingVisitor{}
+		LexDispatched(StringReader("(!)"), rv.visit, TokenTypeEOF, TokenTypeError, SexpDispatchTable)
+		Expect(rv.tokens).To(Equal([]Token{
+			{Typ: TokenTypeStart, Value: "(", Offset: 0},
+			{Typ: TokenTypeError, Value: "No valid token found", Offset: 1},
+		}))
+	})
+})
@@ -0,0 +1,170 @@
+package lexer
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	// ErrIncomplete is returned by the Error method of a Feeder's reader while a
+	// consumer ran into the end of the input received so far, meaning its result
+	// is not final and has to be retried once more input arrived.
+	ErrIncomplete = errors.New("lexer: need more input")
+	// ErrFinished is returned when writing to a Feeder that has been closed or has
+	// emitted an error token.
+	ErrFinished = errors.New("lexer: feeder finished")
+)
+
+// chunkReader is a BufferedRuneReader over the data written to a Feeder. Until
+// it is closed, reaching the end of its buffer means no more data is available
+// yet, which it reports through Error.
+type chunkReader struct {
+	buffer  []byte
+	pos     int
+	offset  int
+	closed  bool
+	starved bool
+	marks   *MarkStack
+}
+
+func newChunkReader() *chunkReader {
+	reader := &chunkReader{}
+	reader.marks = NewMarkStack(func(offset, pos int) {
+		reader.offset = offset
+		reader.pos = pos
+	})
+	return reader
+}
+
+func (s *chunkReader) Mark() Mark {
+	return s.marks.Push(s.offset, s.pos)
+}
+
+func (s *chunkReader) Offset() int {
+	return s.offset
+}
+
+func (s *chunkReader) Read() rune {
+	if s.EOF() {
+		return '\uFFFD'
+	}
+	r, size := utf8.DecodeRune(s.buffer[s.pos:])
+	s.pos += size
+	s.offset++
+	return r
+}
+
+func (s *chunkReader) Peek() rune {
+	if s.EOF() {
+		return '\uFFFD'
+	}
+	r, _ := utf8.DecodeRune(s.buffer[s.pos:])
+	return r
+}
+
+func (s *chunkReader) Rewind() {
+	s.marks.Rewind()
+}
+
+func (s *chunkReader) Error() error {
+	if s.starved {
+		return ErrIncomplete
+	}
+	return nil
+}
+
+// EOF reports whether no complete rune is available. Unless the reader is
+// closed this marks the reader as starved.
+func (s *chunkReader) EOF() bool {
+	if s.available() {
+		return false
+	}
+	if !s.closed {
+		s.starved = true
+	}
+	return true
+}
+
+func (s *chunkReader) available() bool {
+	rest := s.buffer[s.pos:]
+	return len(rest) > 0 && (s.closed || utf8.FullRune(rest))
+}
+
+// compact drops the data before the current position unless a mark still
+// refers to it.
+func (s *chunkReader) compact() {
+	if s.marks.Len() > 0 || s.pos == 0 {
+		return
+	}
+	s.buffer = s.buffer[:copy(s.buffer, s.buffer[s.pos:])]
+	s.pos = 0
+}
+
+// Feeder lexes input that arrives in chunks, such as protocol messages read
+// from a socket. Every token that is definitely complete is passed to the
+// visitor as soon as it has been written; a token that may continue in the next
+// chunk is held back until more input arrives or the Feeder is closed. The
+// tokens are the same LexStatic produces for the concatenated input.
+type Feeder struct {
+	reader      *chunkReader
+	visitor     Visitor
+	eofToken    TokenType
+	errorToken  TokenType
+	validTokens []TokenConsumer
+	finished    bool
+}
+
+func NewFeeder(visitor Visitor, eofToken, errorToken TokenType, validTokens ...TokenConsumer) *Feeder {
+	return &Feeder{
+		reader:      newChunkReader(),
+		visitor:     visitor,
+		eofToken:    eofToken,
+		errorToken:  errorToken,
+		validTokens: validTokens,
+	}
+}
+
+// Write implements io.Writer, lexing as far as the input allows.
+func (s *Feeder) Write(chunk []byte) (int, error) {
+	if s.finished {
+		return 0, ErrFinished
+	}
+	s.reader.buffer = append(s.reader.buffer, chunk...)
+	s.lex()
+	return len(chunk), nil
+}
+
+// Close marks the end of the input, emitting the held back token and the EOF
+// token.
+func (s *Feeder) Close() error {
+	if s.finished {
+		return nil
+	}
+	s.reader.closed = true
+	s.lex()
+	return nil
+}
+
+// Buffered returns the number of bytes held back for the next token.
+func (s *Feeder) Buffered() int {
+	return len(s.reader.buffer) - s.reader.pos
+}
+
+func (s *Feeder) lex() {
+	input := s.reader
+	for !s.finished {
+		if !input.available() && !input.closed {
+			return
+		}
+		input.starved = false
+		tok, rule := scan(input, s.eofToken, s.errorToken, len(s.validTokens), func(i int) (Token, bool) {
+			return s.validTokens[i](input)
+		})
+		if rule == scanSuspended {
+			return
+		}
+		s.visitor(tok)
+		s.finished = rule == scanEnd
+		input.compact()
+	}
+}
@@ -0,0 +1,90 @@
+package lexer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func feedChunks(input string, size int) []Token {
+	rv := RecordingVisitor{}
+	feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+	data := []byte(input)
+	for len(data) > 0 {
+		n := size
+		if n > len(data) {
+			n = len(data)
+		}
+		written, err := feeder.Write(data[:n])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(written).To(Equal(n))
+		data = data[n:]
+	}
+	Expect(feeder.Close()).To(Succeed())
+	return rv.tokens
+}
+
+var _ = Describe("Feeder", func() {
+	var rv RecordingVisitor
+	BeforeEach(func() {
+		rv = RecordingVisitor{}
+	})
+	It("produces the same tokens as LexStatic for any chunk size", func() {
+		input := "(define (f x)\n\t\"a \\\"b\\\" ü\" ( g  'ç' ))  sym"
+		static := RecordingVisitor{}
+		LexStatic(StringReader(input), static.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		for size := 1; size <= len(input); size++ {
+			Expect(feedChunks(input, size)).To(Equal(static.tokens), "chunk size %d", size)
+		}
+	})
+	It("emits complete tokens as soon as they are written", func() {
+		feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		feeder.Write([]byte("(ab"))
+		Expect(rv.tokens).To(Equal([]Token{{Typ: TokenTypeStart, Value: "(", Offset: 0}}))
+		Expect(feeder.Buffered()).To(Equal(2))
+		feeder.Write([]byte("c "))
+		Expect(rv.tokens).To(HaveLen(2))
+		Expect(rv.tokens[1]).To(Equal(Token{Typ: TokenTypeSymbol, Value: "abc", Offset: 1}))
+		Expect(feeder.Buffered()).To(Equal(1))
+	})
+	It("holds back runes split across chunks", func() {
+		feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		feeder.Write([]byte("'\xc3"))
+		feeder.Write([]byte("\xbc'"))
+		Expect(rv.tokens).To(Equal([]Token{{Typ: TokenTypeString, Value: "'ü'", Offset: 0}}))
+	})
+	It("finalizes the held back token on Close", func() {
+		feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		feeder.Write([]byte("abc"))
+		Expect(rv.tokens).To(BeEmpty())
+		Expect(feeder.Close()).To(Succeed())
+		Expect(rv.tokens).To(Equal(NewTokenGenerator().
+			T(TokenTypeSymbol, "abc").
+			T(TokenTypeEOF, "").
+			Build()))
+	})
+	It("produces an error token for unterminated input on Close", func() {
+		feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		feeder.Write([]byte("(\"abc"))
+		Expect(rv.tokens).To(HaveLen(1))
+		feeder.Close()
+		Expect(rv.tokens).To(Equal(NewTokenGenerator().
+			T(TokenTypeStart, "(").
+			T(TokenTypeError, "No valid token found").
+			Build()))
+	})
+	It("stops after an error token", func() {
+		feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		feeder.Write([]byte("(!"))
+		Expect(rv.tokens[len(rv.tokens)-1].Typ).To(Equal(TokenTypeError))
+		_, err := feeder.Write([]byte(")"))
+		Expect(err).To(Equal(ErrFinished))
+	})
+	It("only buffers the pending token", func() {
+		feeder := NewFeeder(rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		for i := 0; i < 1000; i++ {
+			feeder.Write([]byte("(abc \"def\") "))
+			Expect(feeder.Buffered()).To(BeNumerically("<=", 1))
+		}
+		Expect(cap(feeder.reader.buffer)).To(BeNumerically("<", 64))
+	})
+})
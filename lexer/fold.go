@@ -0,0 +1,91 @@
+package lexer
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+// ConsumeTextFold is the case-insensitive variant of ConsumeText, comparing
+// runes by full Unicode case folding, so that e.g. "STRASSE" also matches
+// "Straße". Value holds the lexeme as found in the input, Data the canonical
+// text.
+func ConsumeTextFold(typ TokenType, text string) TokenConsumer {
+	folded := cases.Fold().String(text)
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		value, ok := consumeFolded(input, folded, cases.Fold())
+		if !ok {
+			return Token{}, false
+		}
+		tok := t(typ, value)
+		tok.Data = text
+		return tok, true
+	})
+}
+
+// ConsumeKeywordFold consumes the longest of keywords matching the input
+// case-insensitively, unless it is directly followed by a rune of word, so that
+// a keyword does not match the start of a longer identifier. A nil word set
+// disables that check. Value holds the lexeme as found in the input, Data the
+// keyword as listed.
+func ConsumeKeywordFold(typ TokenType, word RuneSet, keywords ...string) TokenConsumer {
+	type keyword struct {
+		text   string
+		folded string
+	}
+	candidates := make([]keyword, len(keywords))
+	for i, text := range keywords {
+		candidates[i] = keyword{text: text, folded: cases.Fold().String(text)}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i].folded) > len(candidates[j].folded)
+	})
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		caser := cases.Fold()
+		for _, candidate := range candidates {
+			mark := input.Mark()
+			value, ok := consumeFolded(input, candidate.folded, caser)
+			if ok && (word == nil || input.EOF() || !word(input.Peek())) {
+				mark.Commit()
+				tok := t(typ, value)
+				tok.Data = candidate.text
+				return tok, true
+			}
+			mark.Rollback()
+		}
+		return Token{}, false
+	})
+}
+
+// CanonicalValue replaces the Value of tokens matched by consumer with their
+// canonical form, as provided in Data by ConsumeTextFold and
+// ConsumeKeywordFold.
+func CanonicalValue(consumer TokenConsumer) TokenConsumer {
+	return func(input BufferedRuneReader) (Token, bool) {
+		tok, valid := consumer(input)
+		if canonical, ok := tok.Data.(string); valid && ok {
+			tok.Value = canonical
+		}
+		return tok, valid
+	}
+}
+
+// consumeFolded reads runes as long as their case folding continues folded and
+// returns the lexeme read once folded is complete.
+func consumeFolded(input BufferedRuneReader, folded string, caser cases.Caser) (string, bool) {
+	value := newValueBuilder(input)
+	var read strings.Builder
+	for read.Len() < len(folded) {
+		if input.EOF() {
+			return "", false
+		}
+		r := input.Read()
+		value.WriteRune(r)
+		read.WriteString(caser.String(string(r)))
+		if !strings.HasPrefix(folded, read.String()) {
+			return "", false
+		}
+	}
+	return value.String(), true
+}
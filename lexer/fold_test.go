@@ -0,0 +1,75 @@
+package lexer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConsumeTextFold", func() {
+	It("matches regardless of case", func() {
+		for _, input := range []string{"select", "SELECT", "SeLeCt"} {
+			tok, valid := ConsumeTextFold(TokenTypeKeyword, "SELECT")(StringReader(input + " *"))
+			Expect(valid).To(BeTrue())
+			Expect(tok.Value).To(Equal(input))
+			Expect(tok.Data).To(Equal("SELECT"))
+		}
+	})
+	It("uses full case folding", func() {
+		reader := StringReader("Straße!")
+		tok, valid := ConsumeTextFold(TokenTypeKeyword, "STRASSE")(reader)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("Straße"))
+		Expect(reader.Offset()).To(Equal(6))
+		_, valid = ConsumeTextFold(TokenTypeKeyword, "ΣΟΦΟΣ")(StringReader("σοφος"))
+		Expect(valid).To(BeTrue())
+	})
+	It("does not match other text", func() {
+		reader := StringReader("selection")
+		_, valid := ConsumeTextFold(TokenTypeKeyword, "SELECT!")(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+		_, valid = ConsumeTextFold(TokenTypeKeyword, "STRASSE")(StringReader("Straß"))
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeKeywordFold", func() {
+	identifier := RunesOf(sexpSymbolRunes)
+	It("matches the longest keyword", func() {
+		reader := StringReader("Order By x")
+		tok, valid := ConsumeKeywordFold(TokenTypeKeyword, identifier, "ORDER", "ORDER BY")(reader)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("Order By"))
+		Expect(tok.Data).To(Equal("ORDER BY"))
+	})
+	It("does not match the start of an identifier", func() {
+		reader := StringReader("iffy")
+		_, valid := ConsumeKeywordFold(TokenTypeKeyword, identifier, "IF")(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+		_, valid = ConsumeKeywordFold(TokenTypeKeyword, nil, "IF")(StringReader("iffy"))
+		Expect(valid).To(BeTrue())
+	})
+	It("matches at the end of the input", func() {
+		tok, valid := ConsumeKeywordFold(TokenTypeKeyword, identifier, "END")(StringReader("end"))
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(Equal("END"))
+	})
+})
+
+var _ = Describe("CanonicalValue", func() {
+	It("replaces the lexeme by the canonical form", func() {
+		rv := RecordingVisitor{}
+		LexStatic(StringReader("Print x"), rv.visit, TokenTypeEOF, TokenTypeError,
+			CanonicalValue(ConsumeKeywordFold(TokenTypeKeyword, RunesOf(sexpSymbolRunes), "PRINT")),
+			ConsumeRunes(TokenTypeSymbol, sexpSymbolRunes),
+			ConsumeRunes(TokenTypeWhitespace, " "),
+		)
+		Expect(rv.tokens).To(Equal([]Token{
+			{Typ: TokenTypeKeyword, Value: "PRINT", Offset: 0, Data: "PRINT"},
+			{Typ: TokenTypeWhitespace, Value: " ", Offset: 5},
+			{Typ: TokenTypeSymbol, Value: "x", Offset: 6},
+			{Typ: TokenTypeEOF, Offset: 7},
+		}))
+	})
+})
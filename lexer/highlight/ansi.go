@@ -0,0 +1,168 @@
+package highlight
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ColorMode selects the palette used for terminal output.
+type ColorMode int
+
+const (
+	// ANSI16 maps colors to the 16 standard terminal colors.
+	ANSI16 ColorMode = iota
+	// ANSI256 maps colors to the xterm 256 color palette.
+	ANSI256
+	// TrueColor emits 24 bit colors.
+	TrueColor
+)
+
+// ANSIFormatter writes terminal escape sequences. Styles are reset before each
+// line break and reapplied afterwards, so every line renders on its own.
+type ANSIFormatter struct {
+	Mode ColorMode
+}
+
+func NewANSIFormatter(mode ColorMode) *ANSIFormatter {
+	return &ANSIFormatter{
+		Mode: mode,
+	}
+}
+
+func (s *ANSIFormatter) Format(w io.Writer, spans []Span, theme Theme) error {
+	for _, span := range spans {
+		style, ok := theme[span.Typ]
+		if !ok || span.Typ == "" {
+			if _, err := io.WriteString(w, span.Text); err != nil {
+				return err
+			}
+			continue
+		}
+		sgr, err := s.sgr(style)
+		if err != nil {
+			return err
+		}
+		if sgr == "" {
+			if _, err := io.WriteString(w, span.Text); err != nil {
+				return err
+			}
+			continue
+		}
+		lines := strings.Split(span.Text, "\n")
+		for i, line := range lines {
+			if i > 0 {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return err
+				}
+			}
+			if line == "" {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "\x1b[%sm%s\x1b[0m", sgr, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sgr returns the Select Graphic Rendition parameters for style.
+func (s *ANSIFormatter) sgr(style Style) (string, error) {
+	var params []string
+	if style.Bold {
+		params = append(params, "1")
+	}
+	if style.Italic {
+		params = append(params, "3")
+	}
+	if style.Underline {
+		params = append(params, "4")
+	}
+	for _, c := range []struct {
+		color string
+		base  int
+	}{{style.Color, 30}, {style.Background, 40}} {
+		if c.color == "" {
+			continue
+		}
+		color, err := parseColor(c.color)
+		if err != nil {
+			return "", err
+		}
+		params = append(params, s.colorParams(color, c.base))
+	}
+	return strings.Join(params, ";"), nil
+}
+
+func (s *ANSIFormatter) colorParams(color rgb, base int) string {
+	switch s.Mode {
+	case TrueColor:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, color.r, color.g, color.b)
+	case ANSI256:
+		return fmt.Sprintf("%d;5;%d", base+8, xterm256(color))
+	}
+	index := nearest(color, ansi16Palette)
+	if index >= 8 {
+		return fmt.Sprintf("%d", base+60+index-8)
+	}
+	return fmt.Sprintf("%d", base+index)
+}
+
+var ansi16Palette = []rgb{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// xterm256 picks the closest color of the 6x6x6 cube or the gray ramp.
+func xterm256(color rgb) int {
+	cube := func(v uint8) int {
+		best := 0
+		for i, level := range cubeLevels {
+			if abs(int(v)-level) < abs(int(v)-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := cube(color.r), cube(color.g), cube(color.b)
+	cubeColor := rgb{uint8(cubeLevels[r]), uint8(cubeLevels[g]), uint8(cubeLevels[b])}
+	gray := (int(color.r) + int(color.g) + int(color.b)) / 3
+	grayIndex := (gray - 8) / 10
+	if grayIndex < 0 {
+		grayIndex = 0
+	} else if grayIndex > 23 {
+		grayIndex = 23
+	}
+	level := uint8(8 + grayIndex*10)
+	if distance(color, rgb{level, level, level}) < distance(color, cubeColor) {
+		return 232 + grayIndex
+	}
+	return 16 + 36*r + 6*g + b
+}
+
+func nearest(color rgb, palette []rgb) int {
+	best := 0
+	for i, candidate := range palette {
+		if distance(color, candidate) < distance(color, palette[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func distance(a, b rgb) int {
+	dr, dg, db := int(a.r)-int(b.r), int(a.g)-int(b.g), int(a.b)-int(b.b)
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,45 @@
+package highlight
+
+import (
+	"regexp"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var escapeSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func formatANSI(mode ColorMode, source string) string {
+	var b strings.Builder
+	Expect(Highlight(&b, source, tokenize(source), theme, NewANSIFormatter(mode))).To(Succeed())
+	return b.String()
+}
+
+var _ = Describe("ANSIFormatter", func() {
+	It("writes 16 color escapes", func() {
+		Expect(formatANSI(ANSI16, "(a)")).To(Equal("\x1b[1;91m(\x1b[0m\x1b[34ma\x1b[0m\x1b[1;91m)\x1b[0m"))
+	})
+	It("writes 256 color escapes", func() {
+		Expect(formatANSI(ANSI256, "a")).To(Equal("\x1b[38;5;21ma\x1b[0m"))
+	})
+	It("writes true color escapes", func() {
+		Expect(formatANSI(TrueColor, "a")).To(Equal("\x1b[38;2;0;0;255ma\x1b[0m"))
+	})
+	It("resets styles around line breaks", func() {
+		Expect(formatANSI(TrueColor, "\"a\nb\"")).To(Equal("\x1b[3;38;2;0;170;0m\"a\x1b[0m\n\x1b[3;38;2;0;170;0mb\"\x1b[0m"))
+	})
+	It("preserves the source when escapes are removed", func() {
+		source := "(define (f x)\n\t\"a \\\" b\"  (g 'h'))\n"
+		Expect(escapeSequence.ReplaceAllString(formatANSI(ANSI256, source), "")).To(Equal(source))
+	})
+	It("maps grays onto the gray ramp", func() {
+		Expect(xterm256(rgb{128, 128, 128})).To(Equal(244))
+		Expect(xterm256(rgb{255, 0, 0})).To(Equal(196))
+	})
+	It("reports invalid colors", func() {
+		var b strings.Builder
+		err := Highlight(&b, "a", tokenize("a"), Theme{typSymbol: {Color: "blue"}}, NewANSIFormatter(ANSI16))
+		Expect(err).To(HaveOccurred())
+	})
+})
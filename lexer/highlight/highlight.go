@@ -0,0 +1,124 @@
+// Package highlight renders a token stream together with its source as
+// highlighted text for terminals, HTML and LaTeX.
+package highlight
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+// Style describes how tokens of one type are rendered. Colors are given as
+// "#rrggbb" or "#rgb"; empty colors keep the default.
+type Style struct {
+	// Class is used as CSS class name by the HTML formatter and defaults to the
+	// lower cased token type.
+	Class      string
+	Color      string
+	Background string
+	Bold       bool
+	Italic     bool
+	Underline  bool
+}
+
+// Theme maps token types to styles. Tokens without a style are written as-is.
+type Theme map[lexer.TokenType]Style
+
+// Formatter writes a sequence of spans covering the whole source to w.
+type Formatter interface {
+	Format(w io.Writer, spans []Span, theme Theme) error
+}
+
+// Span is a piece of the source. Spans that are not covered by a token, such as
+// trivia dropped from the token stream, have an empty Typ.
+type Span struct {
+	Typ  lexer.TokenType
+	Text string
+}
+
+// Highlight writes source to w using formatter, styling the parts covered by
+// tokens according to theme.
+func Highlight(w io.Writer, source string, tokens []lexer.Token, theme Theme, formatter Formatter) error {
+	return formatter.Format(w, Spans(source, tokens), theme)
+}
+
+// Spans splits source into spans so that their concatenation is exactly the
+// source. Token offsets are counted in runes. A token covers as many runes as
+// its value but never extends beyond the start of the following token; all text
+// between tokens becomes an unstyled span.
+func Spans(source string, tokens []lexer.Token) []Span {
+	var (
+		spans  []Span
+		offset int
+		pos    int
+	)
+	// advance moves pos forward to the rune offset target and returns the text passed.
+	advance := func(target int) string {
+		start := pos
+		for offset < target && pos < len(source) {
+			_, size := utf8.DecodeRuneInString(source[pos:])
+			pos += size
+			offset++
+		}
+		return source[start:pos]
+	}
+	for i, tok := range tokens {
+		if tok.Value == "" || tok.Offset < offset {
+			continue
+		}
+		if gap := advance(tok.Offset); gap != "" {
+			spans = append(spans, Span{Text: gap})
+		}
+		end := tok.Offset + utf8.RuneCountInString(tok.Value)
+		if i+1 < len(tokens) && tokens[i+1].Offset < end && tokens[i+1].Offset >= tok.Offset {
+			end = tokens[i+1].Offset
+		}
+		if text := advance(end); text != "" {
+			spans = append(spans, Span{Typ: tok.Typ, Text: text})
+		}
+	}
+	if pos < len(source) {
+		spans = append(spans, Span{Text: source[pos:]})
+	}
+	return spans
+}
+
+// class returns the CSS class of the style of typ.
+func (s Theme) class(typ lexer.TokenType) string {
+	if style, ok := s[typ]; ok && style.Class != "" {
+		return style.Class
+	}
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, string(typ)))
+}
+
+type rgb struct {
+	r, g, b uint8
+}
+
+func parseColor(color string) (rgb, error) {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return rgb{}, fmt.Errorf("invalid color %q", color)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgb{}, fmt.Errorf("invalid color %q", color)
+	}
+	return rgb{r: uint8(value >> 16), g: uint8(value >> 8), b: uint8(value)}, nil
+}
+
+func (s rgb) hex() string {
+	return fmt.Sprintf("%02x%02x%02x", s.r, s.g, s.b)
+}
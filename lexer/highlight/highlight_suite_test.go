@@ -0,0 +1,13 @@
+package highlight
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHighlight(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Highlight Suite")
+}
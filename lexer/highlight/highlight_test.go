@@ -0,0 +1,103 @@
+package highlight
+
+import (
+	"unicode"
+
+	"github.com/mtrense/parsertk/lexer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	typStart  lexer.TokenType = "START"
+	typEnd    lexer.TokenType = "END"
+	typSymbol lexer.TokenType = "SYMBOL"
+	typString lexer.TokenType = "STRING"
+	typWS     lexer.TokenType = "WS"
+)
+
+var sexpTokens = []lexer.TokenConsumer{
+	lexer.ConsumeSingleRune(typStart, '('),
+	lexer.ConsumeSingleRune(typEnd, ')'),
+	lexer.ConsumeRunes(typSymbol, "abcdefghijklmnopqrstuvwxyzäöü"),
+	lexer.ConsumeCharacterClass(typWS, unicode.White_Space),
+	lexer.ConsumeString(typString),
+}
+
+var theme = Theme{
+	typStart:  {Color: "#f00", Bold: true},
+	typEnd:    {Color: "#f00", Bold: true},
+	typSymbol: {Class: "sym", Color: "#0000ff"},
+	typString: {Color: "#00aa00", Italic: true},
+}
+
+func tokenize(source string) []lexer.Token {
+	var tokens []lexer.Token
+	lexer.LexStatic(lexer.StringReader(source), func(tok lexer.Token) {
+		tokens = append(tokens, tok)
+	}, "EOF", "ERR", sexpTokens...)
+	return tokens
+}
+
+func withoutWhitespace(tokens []lexer.Token) []lexer.Token {
+	var filtered []lexer.Token
+	for _, tok := range tokens {
+		if tok.Typ != typWS {
+			filtered = append(filtered, tok)
+		}
+	}
+	return filtered
+}
+
+func joined(spans []Span) string {
+	var text string
+	for _, span := range spans {
+		text += span.Text
+	}
+	return text
+}
+
+var _ = Describe("Spans", func() {
+	It("covers the source exactly", func() {
+		source := "(äb \"c\\\"d\"\n  (e))"
+		spans := Spans(source, tokenize(source))
+		Expect(joined(spans)).To(Equal(source))
+		Expect(spans[1]).To(Equal(Span{Typ: typSymbol, Text: "äb"}))
+	})
+	It("keeps trivia missing from the token stream as unstyled spans", func() {
+		source := "( a\t b )"
+		spans := Spans(source, withoutWhitespace(tokenize(source)))
+		Expect(spans).To(Equal([]Span{
+			{Typ: typStart, Text: "("},
+			{Text: " "},
+			{Typ: typSymbol, Text: "a"},
+			{Text: "\t "},
+			{Typ: typSymbol, Text: "b"},
+			{Text: " "},
+			{Typ: typEnd, Text: ")"},
+		}))
+	})
+	It("keeps text after the last token", func() {
+		source := "(a !!"
+		Expect(joined(Spans(source, tokenize(source)))).To(Equal(source))
+	})
+})
+
+var _ = Describe("Theme", func() {
+	It("derives CSS classes from token types", func() {
+		Expect(theme.class(typStart)).To(Equal("start"))
+		Expect(theme.class(typSymbol)).To(Equal("sym"))
+		Expect(theme.class("A.B")).To(Equal("a_b"))
+	})
+})
+
+var _ = Describe("parseColor", func() {
+	It("parses long and short hex colors", func() {
+		Expect(parseColor("#102030")).To(Equal(rgb{0x10, 0x20, 0x30}))
+		Expect(parseColor("#abc")).To(Equal(rgb{0xaa, 0xbb, 0xcc}))
+	})
+	It("rejects invalid colors", func() {
+		_, err := parseColor("red")
+		Expect(err).To(HaveOccurred())
+	})
+})
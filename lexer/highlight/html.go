@@ -0,0 +1,107 @@
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+// HTMLFormatter writes a <pre> block with one <span> per styled token, using
+// CSS classes from the theme. Use CSS to obtain a matching stylesheet.
+type HTMLFormatter struct {
+	// ClassPrefix is prepended to every CSS class.
+	ClassPrefix string
+	// LineNumbers prefixes every line with <span class="ln">n</span>.
+	LineNumbers bool
+}
+
+func NewHTMLFormatter() *HTMLFormatter {
+	return &HTMLFormatter{}
+}
+
+func (s *HTMLFormatter) Format(w io.Writer, spans []Span, theme Theme) error {
+	var b strings.Builder
+	b.WriteString(`<pre class="` + s.class("highlight") + `"><code>`)
+	line := 1
+	if s.LineNumbers {
+		s.writeLineNumber(&b, line)
+	}
+	for _, span := range spans {
+		open, close := "", ""
+		if _, ok := theme[span.Typ]; ok && span.Typ != "" {
+			open = `<span class="` + s.class(theme.class(span.Typ)) + `">`
+			close = "</span>"
+		}
+		for i, text := range strings.Split(span.Text, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+				line++
+				if s.LineNumbers {
+					s.writeLineNumber(&b, line)
+				}
+			}
+			if text == "" {
+				continue
+			}
+			b.WriteString(open)
+			b.WriteString(html.EscapeString(text))
+			b.WriteString(close)
+		}
+	}
+	b.WriteString("</code></pre>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s *HTMLFormatter) writeLineNumber(b *strings.Builder, line int) {
+	fmt.Fprintf(b, `<span class="%s">%d</span>`, s.class("ln"), line)
+}
+
+// class returns the prefixed class escaped for use in an attribute.
+func (s *HTMLFormatter) class(name string) string {
+	return html.EscapeString(s.ClassPrefix + name)
+}
+
+// CSS returns a stylesheet defining the classes used by Format for theme.
+func (s *HTMLFormatter) CSS(theme Theme) (string, error) {
+	types := make([]string, 0, len(theme))
+	for typ := range theme {
+		types = append(types, string(typ))
+	}
+	sort.Strings(types)
+	var b strings.Builder
+	fmt.Fprintf(&b, ".%sln { user-select: none; opacity: 0.5; margin-right: 1em; }\n", s.ClassPrefix)
+	for _, typ := range types {
+		style := theme[lexer.TokenType(typ)]
+		var rules []string
+		if style.Color != "" {
+			color, err := parseColor(style.Color)
+			if err != nil {
+				return "", err
+			}
+			rules = append(rules, "color: #"+color.hex()+";")
+		}
+		if style.Background != "" {
+			color, err := parseColor(style.Background)
+			if err != nil {
+				return "", err
+			}
+			rules = append(rules, "background-color: #"+color.hex()+";")
+		}
+		if style.Bold {
+			rules = append(rules, "font-weight: bold;")
+		}
+		if style.Italic {
+			rules = append(rules, "font-style: italic;")
+		}
+		if style.Underline {
+			rules = append(rules, "text-decoration: underline;")
+		}
+		fmt.Fprintf(&b, ".%s%s { %s }\n", s.ClassPrefix, theme.class(lexer.TokenType(typ)), strings.Join(rules, " "))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,50 @@
+package highlight
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var htmlTag = regexp.MustCompile("<[^>]*>")
+
+func formatHTML(formatter *HTMLFormatter, source string) string {
+	var b strings.Builder
+	Expect(Highlight(&b, source, tokenize(source), theme, formatter)).To(Succeed())
+	return b.String()
+}
+
+var _ = Describe("HTMLFormatter", func() {
+	It("wraps styled tokens in spans with CSS classes", func() {
+		Expect(formatHTML(NewHTMLFormatter(), "(a \"<b>\")")).To(Equal(
+			`<pre class="highlight"><code><span class="start">(</span><span class="sym">a</span> ` +
+				`<span class="string">&#34;&lt;b&gt;&#34;</span><span class="end">)</span></code></pre>` + "\n"))
+	})
+	It("prefixes classes", func() {
+		Expect(formatHTML(&HTMLFormatter{ClassPrefix: "tk-"}, "a")).To(Equal(
+			`<pre class="tk-highlight"><code><span class="tk-sym">a</span></code></pre>` + "\n"))
+	})
+	It("escapes classes", func() {
+		Expect(formatHTML(&HTMLFormatter{ClassPrefix: `"><i `}, "a")).To(Equal(
+			`<pre class="&#34;&gt;&lt;i highlight"><code><span class="&#34;&gt;&lt;i sym">a</span></code></pre>` + "\n"))
+	})
+	It("writes line numbers and splits spans at line breaks", func() {
+		Expect(formatHTML(&HTMLFormatter{LineNumbers: true}, "\"a\nb\"")).To(Equal(
+			`<pre class="highlight"><code><span class="ln">1</span><span class="string">&#34;a</span>` + "\n" +
+				`<span class="ln">2</span><span class="string">b&#34;</span></code></pre>` + "\n"))
+	})
+	It("preserves the source when markup is removed", func() {
+		source := "(define (f x)\n\t\"a \\\" b\"  (g '<h>'))\n"
+		Expect(html.UnescapeString(htmlTag.ReplaceAllString(formatHTML(NewHTMLFormatter(), source), ""))).To(Equal(source + "\n"))
+	})
+	It("generates a stylesheet for the theme", func() {
+		css, err := NewHTMLFormatter().CSS(theme)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(css).To(ContainSubstring(".start { color: #ff0000; font-weight: bold; }\n"))
+		Expect(css).To(ContainSubstring(".sym { color: #0000ff; }\n"))
+		Expect(css).To(ContainSubstring(".string { color: #00aa00; font-style: italic; }\n"))
+	})
+})
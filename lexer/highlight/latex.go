@@ -0,0 +1,82 @@
+package highlight
+
+import (
+	"io"
+	"strings"
+)
+
+// LaTeXFormatter writes a fancyvrb Verbatim environment in which styled tokens
+// are wrapped in \textcolor, \colorbox, \textbf, \textit and \underline. The
+// document needs the fancyvrb and xcolor packages.
+type LaTeXFormatter struct {
+	// Environment replaces the default "Verbatim" environment name.
+	Environment string
+}
+
+func NewLaTeXFormatter() *LaTeXFormatter {
+	return &LaTeXFormatter{}
+}
+
+var latexEscaper = strings.NewReplacer(`\`, `\char92{}`, `{`, `\char123{}`, `}`, `\char125{}`)
+
+func (s *LaTeXFormatter) Format(w io.Writer, spans []Span, theme Theme) error {
+	environment := s.Environment
+	if environment == "" {
+		environment = "Verbatim"
+	}
+	var b strings.Builder
+	b.WriteString(`\begin{` + environment + `}[commandchars=\\\{\}]` + "\n")
+	for _, span := range spans {
+		style, ok := theme[span.Typ]
+		if !ok || span.Typ == "" {
+			b.WriteString(latexEscaper.Replace(span.Text))
+			continue
+		}
+		for i, text := range strings.Split(span.Text, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if text == "" {
+				continue
+			}
+			styled, err := s.style(style, latexEscaper.Replace(text))
+			if err != nil {
+				return err
+			}
+			b.WriteString(styled)
+		}
+	}
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(`\end{` + environment + "}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s *LaTeXFormatter) style(style Style, text string) (string, error) {
+	if style.Bold {
+		text = `\textbf{` + text + `}`
+	}
+	if style.Italic {
+		text = `\textit{` + text + `}`
+	}
+	if style.Underline {
+		text = `\underline{` + text + `}`
+	}
+	if style.Color != "" {
+		color, err := parseColor(style.Color)
+		if err != nil {
+			return "", err
+		}
+		text = `\textcolor[HTML]{` + strings.ToUpper(color.hex()) + `}{` + text + `}`
+	}
+	if style.Background != "" {
+		color, err := parseColor(style.Background)
+		if err != nil {
+			return "", err
+		}
+		text = `\colorbox[HTML]{` + strings.ToUpper(color.hex()) + `}{` + text + `}`
+	}
+	return text, nil
+}
@@ -0,0 +1,33 @@
+package highlight
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func formatLaTeX(source string) string {
+	var b strings.Builder
+	Expect(Highlight(&b, source, tokenize(source), theme, NewLaTeXFormatter())).To(Succeed())
+	return b.String()
+}
+
+var _ = Describe("LaTeXFormatter", func() {
+	It("wraps styled tokens in color and font commands", func() {
+		Expect(formatLaTeX("(a)")).To(Equal(`\begin{Verbatim}[commandchars=\\\{\}]` + "\n" +
+			`\textcolor[HTML]{FF0000}{\textbf{(}}\textcolor[HTML]{0000FF}{a}\textcolor[HTML]{FF0000}{\textbf{)}}` + "\n" +
+			`\end{Verbatim}` + "\n"))
+	})
+	It("escapes command characters", func() {
+		Expect(formatLaTeX(`"{}"\`)).To(ContainSubstring(`\textit{"\char123{}\char125{}"}}\char92{}`))
+	})
+	It("does not add a line break when the source ends with one", func() {
+		Expect(formatLaTeX("a\n")).To(HaveSuffix("{a}\n\\end{Verbatim}\n"))
+	})
+	It("honors a custom environment", func() {
+		var b strings.Builder
+		Expect(Highlight(&b, "a", tokenize("a"), theme, &LaTeXFormatter{Environment: "BVerbatim"})).To(Succeed())
+		Expect(b.String()).To(HavePrefix(`\begin{BVerbatim}`))
+	})
+})
@@ -0,0 +1,70 @@
+package lexer
+
+// InternStats describes the work done by an InternTable.
+type InternStats struct {
+	// Lookups counts the values passed to Intern.
+	Lookups int
+	// Hits counts the lookups answered with an already stored value.
+	Hits int
+	// Entries is the number of distinct values stored.
+	Entries int
+	// Bytes is the total length of the distinct values stored.
+	Bytes int
+	// SavedBytes is the total length of the values replaced by a stored one.
+	SavedBytes int
+}
+
+// InternTable deduplicates token values, so that identical identifiers and
+// keywords share storage instead of each Token retaining its own copy. An
+// InternTable is not safe for concurrent use.
+type InternTable struct {
+	values map[string]string
+	stats  InternStats
+}
+
+func NewInternTable() *InternTable {
+	return &InternTable{
+		values: make(map[string]string),
+	}
+}
+
+// Intern returns the stored value equal to value, storing value if there is
+// none yet.
+func (s *InternTable) Intern(value string) string {
+	s.stats.Lookups++
+	if stored, ok := s.values[value]; ok {
+		s.stats.Hits++
+		s.stats.SavedBytes += len(value)
+		return stored
+	}
+	s.values[value] = value
+	s.stats.Entries++
+	s.stats.Bytes += len(value)
+	return value
+}
+
+// Stats returns the statistics gathered so far.
+func (s *InternTable) Stats() InternStats {
+	return s.stats
+}
+
+// Interned wraps visitor so that every token value is interned in table before
+// the token is passed on. Token types that are not listed are passed on
+// untouched; listing no types interns all tokens. The values are still built
+// by the consumers, so interning reduces the memory retained by the tokens,
+// not the allocations made while lexing.
+func Interned(table *InternTable, visitor Visitor, types ...TokenType) Visitor {
+	return func(tok Token) {
+		if len(types) == 0 {
+			tok.Value = table.Intern(tok.Value)
+		} else {
+			for _, typ := range types {
+				if tok.Typ == typ {
+					tok.Value = table.Intern(tok.Value)
+					break
+				}
+			}
+		}
+		visitor(tok)
+	}
+}
@@ -0,0 +1,48 @@
+package lexer
+
+import (
+	"unsafe"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func sameStorage(a, b string) bool {
+	return len(a) == len(b) && (*[2]uintptr)(unsafe.Pointer(&a))[0] == (*[2]uintptr)(unsafe.Pointer(&b))[0]
+}
+
+var _ = Describe("InternTable", func() {
+	It("returns the first stored value for equal values", func() {
+		table := NewInternTable()
+		first := string([]byte("symbol"))
+		second := string([]byte("symbol"))
+		Expect(sameStorage(first, second)).To(BeFalse())
+		Expect(sameStorage(table.Intern(first), first)).To(BeTrue())
+		Expect(sameStorage(table.Intern(second), first)).To(BeTrue())
+	})
+	It("gathers stats", func() {
+		table := NewInternTable()
+		for _, value := range []string{"abc", "de", "abc", "abc"} {
+			table.Intern(value)
+		}
+		Expect(table.Stats()).To(Equal(InternStats{Lookups: 4, Hits: 2, Entries: 2, Bytes: 5, SavedBytes: 6}))
+	})
+})
+
+var _ = Describe("Interned", func() {
+	It("interns the values of all tokens", func() {
+		table := NewInternTable()
+		rv := RecordingVisitor{}
+		LexStatic(StringReader("(a bb a bb)"), Interned(table, rv.visit), TokenTypeEOF, TokenTypeError, SexpTokens...)
+		Expect(rv.tokens).To(HaveLen(10))
+		Expect(sameStorage(rv.tokens[1].Value, rv.tokens[5].Value)).To(BeTrue())
+		Expect(sameStorage(rv.tokens[3].Value, rv.tokens[7].Value)).To(BeTrue())
+		Expect(table.Stats().Entries).To(Equal(6))
+	})
+	It("interns only the listed token types", func() {
+		table := NewInternTable()
+		rv := RecordingVisitor{}
+		LexStatic(StringReader("(a b)"), Interned(table, rv.visit, TokenTypeSymbol), TokenTypeEOF, TokenTypeError, SexpTokens...)
+		Expect(table.Stats()).To(Equal(InternStats{Lookups: 2, Entries: 2, Bytes: 2}))
+	})
+})
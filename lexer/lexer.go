@@ -8,6 +8,9 @@ type Token struct {
 	Offset int
 	Line   int
 	Column int
+	// Data holds a decoded representation of Value for consumers that produce
+	// one, such as the integer encoded by a binary token.
+	Data interface{}
 }
 
 func (s *Token) Length() int {
@@ -24,33 +27,70 @@ type TokenConsumer func(BufferedRuneReader) (Token, bool)
 
 // LexStatic scans the input using one fixed set of valid Tokens.
 func LexStatic(input BufferedRuneReader, visitor Visitor, eofToken, errorToken TokenType, validTokens ...TokenConsumer) {
+	lex(input, visitor, eofToken, errorToken, func(rune) []TokenConsumer {
+		return validTokens
+	})
+}
+
+// lex drives the main scanning loop, asking candidates for the consumers to try
+// at each position given the next rune.
+func lex(input BufferedRuneReader, visitor Visitor, eofToken, errorToken TokenType, candidates func(r rune) []TokenConsumer) {
 	for {
-		var (
-			tok   Token
-			valid bool = false
-		)
-		if input.EOF() {
-			tok = t(eofToken, "")
-			tok.Offset = input.Offset()
-			visitor(tok)
+		consumers := candidates(input.Peek())
+		tok, rule := scan(input, eofToken, errorToken, len(consumers), func(i int) (Token, bool) {
+			return consumers[i](input)
+		})
+		visitor(tok)
+		if rule < 0 {
 			break
 		}
-		for _, tokenConsumer := range validTokens {
-			offset := input.Mark()
-			tok, valid = tokenConsumer(input)
-			if valid {
-				tok.Offset = offset
-				visitor(tok)
-				break
-			} else {
-				input.Rewind()
-			}
+	}
+}
+
+// scanInput is the part of BufferedRuneReader and BufferedByteReader the
+// scanning loop works on.
+type scanInput interface {
+	Mark() Mark
+	Offset() int
+	Error() error
+	EOF() bool
+}
+
+const (
+	// scanEnd is returned by scan along with the EOF and error tokens, after
+	// which scanning ends.
+	scanEnd = -1
+	// scanSuspended is returned by scan when a consumer ran into the end of the
+	// input received so far.
+	scanSuspended = -2
+)
+
+// scan scans the next token of input, trying n consumers through match in
+// order. It returns the token and the index of the consumer that matched, or
+// scanEnd with the EOF or error token. Matches that consume no input are
+// rejected, since they would make no progress. If a consumer reports
+// ErrIncomplete, the input is rolled back and scanSuspended is returned.
+func scan(input scanInput, eofToken, errorToken TokenType, n int, match func(i int) (Token, bool)) (Token, int) {
+	if input.EOF() {
+		tok := t(eofToken, "")
+		tok.Offset = input.Offset()
+		return tok, scanEnd
+	}
+	for i := 0; i < n; i++ {
+		mark := input.Mark()
+		tok, valid := match(i)
+		if input.Error() == ErrIncomplete {
+			mark.Rollback()
+			return Token{}, scanSuspended
 		}
-		if !valid {
-			tok = t(errorToken, "No valid token found")
-			tok.Offset = input.Offset()
-			visitor(tok)
-			break
+		if valid && input.Offset() > mark.Offset() {
+			mark.Commit()
+			tok.Offset = mark.Offset()
+			return tok, i
 		}
+		mark.Rollback()
 	}
+	tok := t(errorToken, "No valid token found")
+	tok.Offset = input.Offset()
+	return tok, scanEnd
 }
@@ -0,0 +1,114 @@
+package lexer
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// generateSexpCorpus builds a deterministic S-expression document of roughly
+// size bytes using the token kinds of SexpTokens.
+func generateSexpCorpus(size int) string {
+	rnd := rand.New(rand.NewSource(42))
+	var b strings.Builder
+	depth := 0
+	for b.Len() < size {
+		switch n := rnd.Intn(10); {
+		case n < 2:
+			b.WriteString("(")
+			depth++
+		case n < 4 && depth > 0:
+			b.WriteString(")")
+			depth--
+		case n < 7:
+			fmt.Fprintf(&b, "sym%d", rnd.Intn(100))
+		case n < 8:
+			fmt.Fprintf(&b, "\"str %d\"", rnd.Intn(100))
+		default:
+			b.WriteString(strings.Repeat(" ", 1+rnd.Intn(3)))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString(strings.Repeat(")", depth))
+	return b.String()
+}
+
+func BenchmarkLexStatic(b *testing.B) {
+	b.ReportAllocs()
+	corpus := generateSexpCorpus(64 * 1024)
+	b.SetBytes(int64(len(corpus)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LexStatic(StringReader(corpus), func(Token) {}, TokenTypeEOF, TokenTypeError, SexpTokens...)
+	}
+}
+
+func BenchmarkLexDispatched(b *testing.B) {
+	b.ReportAllocs()
+	corpus := generateSexpCorpus(64 * 1024)
+	b.SetBytes(int64(len(corpus)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LexDispatched(StringReader(corpus), func(Token) {}, TokenTypeEOF, TokenTypeError, SexpDispatchTable)
+	}
+}
+
+func BenchmarkLexStaticSourceReader(b *testing.B) {
+	corpus := generateSexpCorpus(64 * 1024)
+	b.SetBytes(int64(len(corpus)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LexStatic(SourceStringReader(corpus), func(Token) {}, TokenTypeEOF, TokenTypeError, SexpTokens...)
+	}
+}
+
+func BenchmarkLexRegexSet(b *testing.B) {
+	corpus := generateSexpCorpus(64 * 1024)
+	b.SetBytes(int64(len(corpus)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SexpRegexSet.Lex(SourceStringReader(corpus), func(Token) {}, TokenTypeEOF, TokenTypeError)
+	}
+}
+
+// benchmarkRetained lexes a large corpus, keeping all tokens, and reports the
+// heap retained by them.
+func benchmarkRetained(b *testing.B, wrap func(Visitor) Visitor) {
+	corpus := generateSexpCorpus(1024 * 1024)
+	b.SetBytes(int64(len(corpus)))
+	b.ReportAllocs()
+	var before, after runtime.MemStats
+	retained := int64(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rv := RecordingVisitor{}
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		b.StartTimer()
+		LexStatic(StringReader(corpus), wrap(rv.visit), TokenTypeEOF, TokenTypeError, SexpTokens...)
+		b.StopTimer()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		retained += int64(after.HeapAlloc) - int64(before.HeapAlloc)
+		runtime.KeepAlive(rv.tokens)
+		b.StartTimer()
+	}
+	b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+}
+
+func BenchmarkLexRetained(b *testing.B) {
+	benchmarkRetained(b, func(visitor Visitor) Visitor {
+		return visitor
+	})
+}
+
+func BenchmarkLexRetainedInterned(b *testing.B) {
+	benchmarkRetained(b, func(visitor Visitor) Visitor {
+		return Interned(NewInternTable(), visitor)
+	})
+}
@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"unicode"
 
 	. "github.com/onsi/ginkgo"
@@ -27,10 +28,12 @@ const (
 	TokenTypeError      TokenType = "ERR"
 )
 
+const sexpSymbolRunes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_$#%&"
+
 var SexpTokens = []TokenConsumer{
 	ConsumeSingleRune(TokenTypeStart, '('),
 	ConsumeSingleRune(TokenTypeEnd, ')'),
-	ConsumeRunes(TokenTypeSymbol, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_$#%&"),
+	ConsumeRunes(TokenTypeSymbol, sexpSymbolRunes),
 	ConsumeCharacterClass(TokenTypeWhitespace, unicode.White_Space),
 	ConsumeString(TokenTypeString),
 }
@@ -131,12 +134,31 @@ var _ = Describe("Lex", func() {
 			T(TokenTypeEOF, "").
 			Build()))
 	})
+	It("does not accumulate marks", func() {
+		reader := StringReader(strings.Repeat("(a \"b\" c)", 100))
+		LexStatic(reader, func(tok Token) {
+			Expect(reader.(*stringReader).marks.Len()).To(BeZero())
+			rv.visit(tok)
+		}, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		Expect(rv.tokens).To(HaveLen(701))
+		Expect(reader.(*stringReader).marks.Len()).To(BeZero())
+	})
 	It("produces an error token when string is not closed", func() {
 		LexStatic(StringReader("\"abcdef"), (&rv).visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
 		Expect(rv.tokens).To(Equal(NewTokenGenerator().
 			T(TokenTypeError, "No valid token found").
 			Build()))
 	})
+	It("rejects matches that consume nothing", func() {
+		empty := func(BufferedRuneReader) (Token, bool) {
+			return t(TokenTypeSymbol, ""), true
+		}
+		LexStatic(StringReader("a"), (&rv).visit, TokenTypeEOF, TokenTypeError, empty, ConsumeRunes(TokenTypeSymbol, "a"))
+		Expect(rv.tokens).To(Equal(NewTokenGenerator().
+			T(TokenTypeSymbol, "a").
+			T(TokenTypeEOF, "").
+			Build()))
+	})
 })
 
 var _ = Describe("consumeString", func() {
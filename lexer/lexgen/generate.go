@@ -0,0 +1,235 @@
+package lexgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/mtrense/parsertk/lexer/dfa"
+)
+
+type tokenConstant struct {
+	Ident string
+	Name  string
+}
+
+type generatedRule struct {
+	Ident string
+	Push  int
+	Pop   bool
+}
+
+type generatedState struct {
+	Accept      int
+	Transitions []dfa.Transition
+}
+
+type generatorInput struct {
+	Spec       *Spec
+	Prefix     string
+	Constants  []tokenConstant
+	EOF        string
+	Error      string
+	Rules      []generatedRule
+	Modes      []string
+	ModeStarts []int
+	States     []generatedState
+}
+
+// Generate compiles spec into gofmt'ed Go source. The generated function has the
+// signature func(lexer.BufferedRuneReader, lexer.Visitor) and emits tokens
+// exactly like lexer.LexStatic, including the trailing EOF or error token.
+func Generate(spec *Spec) ([]byte, error) {
+	spec.applyDefaults()
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	in := generatorInput{
+		Spec:   spec,
+		Prefix: lowerFirst(spec.Function),
+		EOF:    spec.Prefix + spec.EOF,
+		Error:  spec.Prefix + spec.Error,
+		Modes:  spec.modeNames(),
+	}
+	seen := make(map[string]bool)
+	addConstant := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			in.Constants = append(in.Constants, tokenConstant{Ident: spec.Prefix + name, Name: name})
+		}
+	}
+	modes := spec.modes()
+	for _, rule := range spec.Tokens {
+		addConstant(rule.Name)
+		push := -1
+		if rule.Push != "" {
+			push = modes[rule.Push]
+		}
+		in.Rules = append(in.Rules, generatedRule{Ident: spec.Prefix + rule.Name, Push: push, Pop: rule.Pop})
+	}
+	addConstant(spec.EOF)
+	addConstant(spec.Error)
+	for _, mode := range in.Modes {
+		var (
+			rules  []dfa.Rule
+			global []int
+		)
+		for i, rule := range spec.Tokens {
+			if rule.activeIn(mode, spec.InitialMode) {
+				rules = append(rules, dfa.Rule{Pattern: rule.pattern(), Priority: rule.Priority})
+				global = append(global, i)
+			}
+		}
+		machine, err := dfa.Compile(rules...)
+		if err != nil {
+			return nil, fmt.Errorf("mode %s: %w", mode, err)
+		}
+		offset := len(in.States)
+		in.ModeStarts = append(in.ModeStarts, offset)
+		for _, state := range machine.States {
+			generated := generatedState{Accept: -1}
+			if state.Accept >= 0 {
+				generated.Accept = global[state.Accept]
+			}
+			for _, transition := range state.Transitions {
+				transition.Next += offset
+				generated.Transitions = append(generated.Transitions, transition)
+			}
+			in.States = append(in.States, generated)
+		}
+	}
+	var out bytes.Buffer
+	if err := sourceTemplate.Execute(&out, in); err != nil {
+		return nil, err
+	}
+	return format.Source(out.Bytes())
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+var sourceTemplate = template.Must(template.New("lexer").Funcs(template.FuncMap{
+	"rune": func(r rune) string {
+		return fmt.Sprintf("%q", r)
+	},
+	"join": strings.Join,
+}).Parse(`// Code generated by parsertk-lexgen. DO NOT EDIT.
+
+package {{.Spec.Package}}
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const (
+{{- range .Constants}}
+	{{.Ident}} lexer.TokenType = {{printf "%q" .Name}}
+{{- end}}
+)
+
+type {{.Prefix}}Transition struct {
+	lo, hi rune
+	next   int
+}
+
+type {{.Prefix}}State struct {
+	accept      int
+	transitions []{{.Prefix}}Transition
+}
+
+type {{.Prefix}}Rule struct {
+	typ  lexer.TokenType
+	push int
+	pop  bool
+}
+
+// {{.Prefix}}ModeStarts holds the start state of each mode: {{join .Modes ", "}}.
+var {{.Prefix}}ModeStarts = [...]int{ {{- range $i, $s := .ModeStarts}}{{if $i}}, {{end}}{{$s}}{{end -}} }
+
+var {{.Prefix}}Rules = [...]{{.Prefix}}Rule{
+{{- range .Rules}}
+	{ {{- .Ident}}, {{.Push}}, {{.Pop -}} },
+{{- end}}
+}
+
+var {{.Prefix}}States = [...]{{.Prefix}}State{
+{{- range .States}}
+	{ {{- .Accept}}, []{{$.Prefix}}Transition{ {{- range $i, $t := .Transitions}}{{if $i}}, {{end}}{ {{- rune $t.Lo}}, {{rune $t.Hi}}, {{$t.Next -}} }{{end -}} } },
+{{- end}}
+}
+
+func {{.Prefix}}Step(state int, r rune) int {
+	transitions := {{.Prefix}}States[state].transitions
+	i := sort.Search(len(transitions), func(i int) bool {
+		return transitions[i].hi >= r
+	})
+	if i < len(transitions) && transitions[i].lo <= r {
+		return transitions[i].next
+	}
+	return -1
+}
+
+// {{.Prefix}}Value reads the next length runes and returns them as token value.
+func {{.Prefix}}Value(input lexer.BufferedRuneReader, length int) string {
+	if source, ok := input.(lexer.SourceReader); ok {
+		start := input.Offset()
+		for i := 0; i < length; i++ {
+			input.Read()
+		}
+		return source.Since(start)
+	}
+	var value strings.Builder
+	for i := 0; i < length; i++ {
+		value.WriteRune(input.Read())
+	}
+	return value.String()
+}
+
+// {{.Spec.Function}} scans the input, emitting the longest match of the rules of
+// the current mode at every position.
+func {{.Spec.Function}}(input lexer.BufferedRuneReader, visitor lexer.Visitor) {
+	modes := []int{0}
+	for {
+		if input.EOF() {
+			visitor(lexer.Token{Typ: {{.EOF}}, Offset: input.Offset()})
+			return
+		}
+		mark := input.Mark()
+		state := {{.Prefix}}ModeStarts[modes[len(modes)-1]]
+		rule, length := -1, 0
+		for n := 1; !input.EOF(); n++ {
+			state = {{.Prefix}}Step(state, input.Read())
+			if state < 0 {
+				break
+			}
+			if accept := {{.Prefix}}States[state].accept; accept >= 0 {
+				rule, length = accept, n
+			}
+		}
+		mark.Rollback()
+		if rule < 0 {
+			visitor(lexer.Token{Typ: {{.Error}}, Value: "No valid token found", Offset: mark.Offset()})
+			return
+		}
+		visitor(lexer.Token{Typ: {{.Prefix}}Rules[rule].typ, Value: {{.Prefix}}Value(input, length), Offset: mark.Offset()})
+		if {{.Prefix}}Rules[rule].pop && len(modes) > 1 {
+			modes = modes[:len(modes)-1]
+		}
+		if push := {{.Prefix}}Rules[rule].push; push >= 0 {
+			modes = append(modes, push)
+		}
+	}
+}
+`))
@@ -0,0 +1,60 @@
+package lexgen
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func loadSpec(json string) (*Spec, error) {
+	return LoadSpec(strings.NewReader(json))
+}
+
+var _ = Describe("LoadSpec", func() {
+	It("fills in defaults", func() {
+		spec, err := loadSpec(`{"package": "p", "eof": "EOF", "error": "ERR", "tokens": []}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spec.Function).To(Equal("Lex"))
+		Expect(spec.Prefix).To(Equal("TokenType"))
+		Expect(spec.InitialMode).To(Equal("default"))
+	})
+	It("rejects unknown fields", func() {
+		_, err := loadSpec(`{"package": "p", "eof": "EOF", "error": "ERR", "tokenz": []}`)
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects rules without exactly one pattern", func() {
+		_, err := loadSpec(`{"package": "p", "eof": "EOF", "error": "ERR", "tokens": [{"name": "A", "literal": "a", "regex": "a"}]}`)
+		Expect(err).To(MatchError(ContainSubstring("exactly one")))
+	})
+	It("rejects pushes to unknown modes", func() {
+		_, err := loadSpec(`{"package": "p", "eof": "EOF", "error": "ERR", "tokens": [{"name": "A", "literal": "a", "push": "nowhere"}]}`)
+		Expect(err).To(MatchError(ContainSubstring("unknown mode")))
+	})
+	It("rejects names that do not form identifiers", func() {
+		_, err := loadSpec(`{"package": "p", "eof": "EOF", "error": "ERR", "tokens": [{"name": "A-B", "literal": "a"}]}`)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Generate", func() {
+	It("reports rules matching the empty string", func() {
+		spec, _ := loadSpec(`{"package": "p", "eof": "EOF", "error": "ERR", "tokens": [{"name": "A", "regex": "a*"}]}`)
+		_, err := Generate(spec)
+		Expect(err).To(MatchError(ContainSubstring("empty string")))
+	})
+	It("is up to date with the generated example", func() {
+		f, err := os.Open("internal/sexplexer/sexp.json")
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+		spec, err := LoadSpec(f)
+		Expect(err).ToNot(HaveOccurred())
+		source, err := Generate(spec)
+		Expect(err).ToNot(HaveOccurred())
+		expected, err := ioutil.ReadFile("internal/sexplexer/sexp_lexer.go")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(source)).To(Equal(string(expected)))
+	})
+})
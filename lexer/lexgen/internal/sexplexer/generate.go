@@ -0,0 +1,5 @@
+// Package sexplexer is an S-expression lexer generated by parsertk-lexgen. It
+// serves as an example and as a compile test for the generator.
+package sexplexer
+
+//go:generate go run github.com/mtrense/parsertk/cmd/parsertk-lexgen -spec sexp.json -o sexp_lexer.go
@@ -0,0 +1,148 @@
+// Code generated by parsertk-lexgen. DO NOT EDIT.
+
+package sexplexer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const (
+	TokenTypeSTART         lexer.TokenType = "START"
+	TokenTypeEND           lexer.TokenType = "END"
+	TokenTypeTRUE          lexer.TokenType = "TRUE"
+	TokenTypeFALSE         lexer.TokenType = "FALSE"
+	TokenTypeSYMBOL        lexer.TokenType = "SYMBOL"
+	TokenTypeWS            lexer.TokenType = "WS"
+	TokenTypeSTRING        lexer.TokenType = "STRING"
+	TokenTypeCOMMENT_START lexer.TokenType = "COMMENT_START"
+	TokenTypeCOMMENT       lexer.TokenType = "COMMENT"
+	TokenTypeCOMMENT_END   lexer.TokenType = "COMMENT_END"
+	TokenTypeEOF           lexer.TokenType = "EOF"
+	TokenTypeERR           lexer.TokenType = "ERR"
+)
+
+type lexTransition struct {
+	lo, hi rune
+	next   int
+}
+
+type lexState struct {
+	accept      int
+	transitions []lexTransition
+}
+
+type lexRule struct {
+	typ  lexer.TokenType
+	push int
+	pop  bool
+}
+
+// lexModeStarts holds the start state of each mode: default, comment.
+var lexModeStarts = [...]int{0, 19}
+
+var lexRules = [...]lexRule{
+	{TokenTypeSTART, -1, false},
+	{TokenTypeEND, -1, false},
+	{TokenTypeTRUE, -1, false},
+	{TokenTypeFALSE, -1, false},
+	{TokenTypeSYMBOL, -1, false},
+	{TokenTypeWS, -1, false},
+	{TokenTypeSTRING, -1, false},
+	{TokenTypeCOMMENT_START, 1, false},
+	{TokenTypeCOMMENT, -1, false},
+	{TokenTypeCOMMENT_END, -1, true},
+}
+
+var lexStates = [...]lexState{
+	{-1, []lexTransition{{'\t', '\n', 1}, {'\f', '\r', 1}, {' ', ' ', 1}, {'"', '"', 2}, {'#', '#', 3}, {'$', '&', 4}, {'\'', '\'', 5}, {'(', '(', 6}, {')', ')', 7}, {'-', '-', 4}, {'0', '9', 4}, {'A', 'Z', 4}, {'_', '_', 4}, {'a', 'z', 4}}},
+	{5, []lexTransition{{'\t', '\n', 1}, {'\f', '\r', 1}, {' ', ' ', 1}}},
+	{-1, []lexTransition{{'\x00', '!', 8}, {'"', '"', 9}, {'#', '[', 8}, {'\\', '\\', 10}, {']', '\U0010ffff', 8}}},
+	{4, []lexTransition{{'#', '&', 4}, {'-', '-', 4}, {'0', '9', 4}, {'A', 'Z', 4}, {'_', '_', 4}, {'a', 'e', 4}, {'f', 'f', 11}, {'g', 's', 4}, {'t', 't', 12}, {'u', 'z', 4}, {'|', '|', 13}}},
+	{4, []lexTransition{{'#', '&', 4}, {'-', '-', 4}, {'0', '9', 4}, {'A', 'Z', 4}, {'_', '_', 4}, {'a', 'z', 4}}},
+	{-1, []lexTransition{{'\x00', '&', 14}, {'\'', '\'', 15}, {'(', '[', 14}, {'\\', '\\', 16}, {']', '\U0010ffff', 14}}},
+	{0, []lexTransition{}},
+	{1, []lexTransition{}},
+	{-1, []lexTransition{{'\x00', '!', 8}, {'"', '"', 9}, {'#', '[', 8}, {'\\', '\\', 10}, {']', '\U0010ffff', 8}}},
+	{6, []lexTransition{}},
+	{-1, []lexTransition{{'\x00', '\t', 17}, {'\v', '\U0010ffff', 17}}},
+	{3, []lexTransition{{'#', '&', 4}, {'-', '-', 4}, {'0', '9', 4}, {'A', 'Z', 4}, {'_', '_', 4}, {'a', 'z', 4}}},
+	{2, []lexTransition{{'#', '&', 4}, {'-', '-', 4}, {'0', '9', 4}, {'A', 'Z', 4}, {'_', '_', 4}, {'a', 'z', 4}}},
+	{7, []lexTransition{}},
+	{-1, []lexTransition{{'\x00', '&', 14}, {'\'', '\'', 15}, {'(', '[', 14}, {'\\', '\\', 16}, {']', '\U0010ffff', 14}}},
+	{6, []lexTransition{}},
+	{-1, []lexTransition{{'\x00', '\t', 18}, {'\v', '\U0010ffff', 18}}},
+	{-1, []lexTransition{{'\x00', '!', 8}, {'"', '"', 9}, {'#', '[', 8}, {'\\', '\\', 10}, {']', '\U0010ffff', 8}}},
+	{-1, []lexTransition{{'\x00', '&', 14}, {'\'', '\'', 15}, {'(', '[', 14}, {'\\', '\\', 16}, {']', '\U0010ffff', 14}}},
+	{-1, []lexTransition{{'\x00', '{', 20}, {'|', '|', 21}, {'}', '\U0010ffff', 20}}},
+	{8, []lexTransition{{'\x00', '{', 20}, {'|', '|', 22}, {'}', '\U0010ffff', 20}}},
+	{8, []lexTransition{{'\x00', '"', 23}, {'#', '#', 24}, {'$', '{', 23}, {'|', '|', 22}, {'}', '\U0010ffff', 23}}},
+	{-1, []lexTransition{{'\x00', '"', 23}, {'$', '{', 23}, {'|', '|', 22}, {'}', '\U0010ffff', 23}}},
+	{8, []lexTransition{{'\x00', '{', 20}, {'|', '|', 22}, {'}', '\U0010ffff', 20}}},
+	{9, []lexTransition{}},
+}
+
+func lexStep(state int, r rune) int {
+	transitions := lexStates[state].transitions
+	i := sort.Search(len(transitions), func(i int) bool {
+		return transitions[i].hi >= r
+	})
+	if i < len(transitions) && transitions[i].lo <= r {
+		return transitions[i].next
+	}
+	return -1
+}
+
+// lexValue reads the next length runes and returns them as token value.
+func lexValue(input lexer.BufferedRuneReader, length int) string {
+	if source, ok := input.(lexer.SourceReader); ok {
+		start := input.Offset()
+		for i := 0; i < length; i++ {
+			input.Read()
+		}
+		return source.Since(start)
+	}
+	var value strings.Builder
+	for i := 0; i < length; i++ {
+		value.WriteRune(input.Read())
+	}
+	return value.String()
+}
+
+// Lex scans the input, emitting the longest match of the rules of
+// the current mode at every position.
+func Lex(input lexer.BufferedRuneReader, visitor lexer.Visitor) {
+	modes := []int{0}
+	for {
+		if input.EOF() {
+			visitor(lexer.Token{Typ: TokenTypeEOF, Offset: input.Offset()})
+			return
+		}
+		mark := input.Mark()
+		state := lexModeStarts[modes[len(modes)-1]]
+		rule, length := -1, 0
+		for n := 1; !input.EOF(); n++ {
+			state = lexStep(state, input.Read())
+			if state < 0 {
+				break
+			}
+			if accept := lexStates[state].accept; accept >= 0 {
+				rule, length = accept, n
+			}
+		}
+		mark.Rollback()
+		if rule < 0 {
+			visitor(lexer.Token{Typ: TokenTypeERR, Value: "No valid token found", Offset: mark.Offset()})
+			return
+		}
+		visitor(lexer.Token{Typ: lexRules[rule].typ, Value: lexValue(input, length), Offset: mark.Offset()})
+		if lexRules[rule].pop && len(modes) > 1 {
+			modes = modes[:len(modes)-1]
+		}
+		if push := lexRules[rule].push; push >= 0 {
+			modes = append(modes, push)
+		}
+	}
+}
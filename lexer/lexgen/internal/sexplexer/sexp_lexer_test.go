@@ -0,0 +1,76 @@
+package sexplexer
+
+import (
+	"unicode"
+
+	"github.com/mtrense/parsertk/lexer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func lex(input lexer.BufferedRuneReader) []lexer.Token {
+	var tokens []lexer.Token
+	Lex(input, func(tok lexer.Token) {
+		tokens = append(tokens, tok)
+	})
+	return tokens
+}
+
+var _ = Describe("Lex", func() {
+	It("produces the same tokens as LexStatic with equivalent consumers", func() {
+		input := "(define (f x) \"a b\" ( g  'c' )\n\t(h $t%e&s0t9))"
+		var expected []lexer.Token
+		lexer.LexStatic(lexer.StringReader(input), func(tok lexer.Token) {
+			expected = append(expected, tok)
+		}, TokenTypeEOF, TokenTypeERR,
+			lexer.ConsumeSingleRune(TokenTypeSTART, '('),
+			lexer.ConsumeSingleRune(TokenTypeEND, ')'),
+			lexer.ConsumeRunes(TokenTypeSYMBOL, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_$#%&"),
+			lexer.ConsumeCharacterClass(TokenTypeWS, unicode.White_Space),
+			lexer.ConsumeString(TokenTypeSTRING),
+		)
+		Expect(lex(lexer.StringReader(input))).To(Equal(expected))
+		Expect(lex(lexer.SourceStringReader(input))).To(Equal(expected))
+	})
+	It("keeps escapes in string values", func() {
+		Expect(lex(lexer.StringReader(`"a\"b"`))).To(Equal(lexer.NewTokenGenerator().
+			T(TokenTypeSTRING, `"a\"b"`).
+			T(TokenTypeEOF, "").
+			Build()))
+	})
+	It("resolves ties by priority and prefers longer matches", func() {
+		Expect(lex(lexer.StringReader("#t #tx"))).To(Equal(lexer.NewTokenGenerator().
+			T(TokenTypeTRUE, "#t").
+			T(TokenTypeWS, " ").
+			T(TokenTypeSYMBOL, "#tx").
+			T(TokenTypeEOF, "").
+			Build()))
+	})
+	It("switches modes", func() {
+		Expect(lex(lexer.StringReader("#| (a) |#()"))).To(Equal(lexer.NewTokenGenerator().
+			T(TokenTypeCOMMENT_START, "#|").
+			T(TokenTypeCOMMENT, " (a) ").
+			T(TokenTypeCOMMENT_END, "|#").
+			T(TokenTypeSTART, "(").
+			T(TokenTypeEND, ")").
+			T(TokenTypeEOF, "").
+			Build()))
+	})
+	It("closes comments ending in pipes", func() {
+		Expect(lex(lexer.StringReader("#|a||#()"))).To(Equal(lexer.NewTokenGenerator().
+			T(TokenTypeCOMMENT_START, "#|").
+			T(TokenTypeCOMMENT, "a").
+			T(TokenTypeCOMMENT, "|").
+			T(TokenTypeCOMMENT_END, "|#").
+			T(TokenTypeSTART, "(").
+			T(TokenTypeEND, ")").
+			T(TokenTypeEOF, "").
+			Build()))
+	})
+	It("produces an error token when no rule matches", func() {
+		Expect(lex(lexer.StringReader("(!"))).To(Equal(lexer.NewTokenGenerator().
+			T(TokenTypeSTART, "(").
+			T(TokenTypeERR, "No valid token found").
+			Build()))
+	})
+})
@@ -0,0 +1,13 @@
+package sexplexer
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSexplexer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sexplexer Suite")
+}
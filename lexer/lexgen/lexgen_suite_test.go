@@ -0,0 +1,13 @@
+package lexgen
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLexgen(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lexgen Suite")
+}
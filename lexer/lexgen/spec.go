@@ -0,0 +1,162 @@
+// Package lexgen compiles a declarative lexer specification into a standalone Go
+// source file containing a table-driven DFA lexer.
+package lexgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"regexp"
+)
+
+// Spec describes a lexer to be generated.
+type Spec struct {
+	// Package is the package clause of the generated file.
+	Package string `json:"package"`
+	// Function is the name of the generated lexing function, "Lex" by default.
+	Function string `json:"function,omitempty"`
+	// Prefix is prepended to token names to form the generated TokenType
+	// constants, "TokenType" by default.
+	Prefix string `json:"prefix,omitempty"`
+	// EOF and Error name the token types emitted at the end of input and when no
+	// rule matches.
+	EOF   string `json:"eof"`
+	Error string `json:"error"`
+	// InitialMode is the mode lexing starts in, "default" by default.
+	InitialMode string      `json:"initialMode,omitempty"`
+	Tokens      []TokenRule `json:"tokens"`
+}
+
+// TokenRule is a single rule producing tokens of type Name. Exactly one of
+// Literal, Class and Regex must be set. Class is the body of a regular
+// expression character class such as "a-zA-Z_" and matches one or more runes.
+//
+// At every position the longest match wins; ties are broken by the highest
+// Priority and then by the order of the rules. Modes lists the modes the rule
+// is active in and defaults to the initial mode. After emitting a token the
+// rule pops the current mode if Pop is set and then enters Push if given.
+type TokenRule struct {
+	Name     string   `json:"name"`
+	Literal  string   `json:"literal,omitempty"`
+	Class    string   `json:"class,omitempty"`
+	Regex    string   `json:"regex,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Modes    []string `json:"modes,omitempty"`
+	Push     string   `json:"push,omitempty"`
+	Pop      bool     `json:"pop,omitempty"`
+}
+
+// LoadSpec reads a JSON encoded Spec and fills in defaults.
+func LoadSpec(r io.Reader) (*Spec, error) {
+	spec := &Spec{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return nil, err
+	}
+	spec.applyDefaults()
+	return spec, spec.Validate()
+}
+
+func (s *Spec) applyDefaults() {
+	if s.Function == "" {
+		s.Function = "Lex"
+	}
+	if s.Prefix == "" {
+		s.Prefix = "TokenType"
+	}
+	if s.InitialMode == "" {
+		s.InitialMode = "default"
+	}
+}
+
+// Validate checks the spec for missing or inconsistent settings.
+func (s *Spec) Validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return fmt.Errorf("invalid package name %q", s.Package)
+	}
+	if !token.IsIdentifier(s.Function) {
+		return fmt.Errorf("invalid function name %q", s.Function)
+	}
+	if s.EOF == "" || s.Error == "" {
+		return fmt.Errorf("eof and error token types are required")
+	}
+	for _, name := range []string{s.EOF, s.Error} {
+		if !token.IsIdentifier(s.Prefix + name) {
+			return fmt.Errorf("token name %q does not form a valid identifier", name)
+		}
+	}
+	modes := s.modes()
+	for i, rule := range s.Tokens {
+		if !token.IsIdentifier(s.Prefix + rule.Name) {
+			return fmt.Errorf("token %d: name %q does not form a valid identifier", i, rule.Name)
+		}
+		set := 0
+		for _, value := range []string{rule.Literal, rule.Class, rule.Regex} {
+			if value != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("token %d (%s): exactly one of literal, class and regex must be set", i, rule.Name)
+		}
+		if rule.Push != "" {
+			if _, ok := modes[rule.Push]; !ok {
+				return fmt.Errorf("token %d (%s): push to unknown mode %q", i, rule.Name, rule.Push)
+			}
+		}
+	}
+	return nil
+}
+
+// pattern returns the rule as a regular expression.
+func (s *TokenRule) pattern() string {
+	switch {
+	case s.Literal != "":
+		return regexp.QuoteMeta(s.Literal)
+	case s.Class != "":
+		return "[" + s.Class + "]+"
+	}
+	return s.Regex
+}
+
+func (s *TokenRule) activeIn(mode, initialMode string) bool {
+	if len(s.Modes) == 0 {
+		return mode == initialMode
+	}
+	for _, m := range s.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// modes returns the index of every mode used by the spec, the initial mode
+// being 0 and the others numbered in order of first appearance.
+func (s *Spec) modes() map[string]int {
+	modes := map[string]int{s.InitialMode: 0}
+	names := []string{s.InitialMode}
+	add := func(mode string) {
+		if _, ok := modes[mode]; !ok {
+			modes[mode] = len(names)
+			names = append(names, mode)
+		}
+	}
+	for _, rule := range s.Tokens {
+		for _, mode := range rule.Modes {
+			add(mode)
+		}
+	}
+	return modes
+}
+
+func (s *Spec) modeNames() []string {
+	modes := s.modes()
+	names := make([]string, len(modes))
+	for name, i := range modes {
+		names[i] = name
+	}
+	return names
+}
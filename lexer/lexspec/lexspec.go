@@ -0,0 +1,222 @@
+// Package lexspec builds lexers from declarative rule sets kept in YAML, JSON or
+// any other configuration format supported by viper, so lexers can be adjusted
+// without recompiling.
+package lexspec
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mtrense/parsertk/lexer"
+	"github.com/spf13/viper"
+)
+
+// Spec describes a lexer. Rules are tried in order, like the consumers passed
+// to lexer.LexStatic.
+type Spec struct {
+	EOF         string `mapstructure:"eof"`
+	Error       string `mapstructure:"error"`
+	InitialMode string `mapstructure:"initialMode"`
+	Tokens      []Rule `mapstructure:"tokens"`
+}
+
+// Rule describes one TokenConsumer. Kind selects the consumer and determines
+// which of the parameters are used:
+//
+//	rune       ConsumeSingleRune with each rune of Runes
+//	runes      ConsumeRunes with Runes
+//	class      ConsumeCharacterClass with the unicode categories, scripts or properties in Classes
+//	text       ConsumeText with Text
+//	regex      ConsumeRegex with Regex
+//	string     ConsumeDelimitedString with Delimiters, defaulting to double and single quotes
+//
+// If Validate is set, the consumer is wrapped in ConsumeRegexpValidated. Modes,
+// Push and Pop have the same meaning as for lexer.ModeRule; a rule without
+// Modes belongs to the initial mode.
+type Rule struct {
+	Type       string   `mapstructure:"type"`
+	Kind       string   `mapstructure:"kind"`
+	Runes      string   `mapstructure:"runes"`
+	Classes    []string `mapstructure:"classes"`
+	Text       string   `mapstructure:"text"`
+	Regex      string   `mapstructure:"regex"`
+	Delimiters string   `mapstructure:"delimiters"`
+	Validate   string   `mapstructure:"validate"`
+	Modes      []string `mapstructure:"modes"`
+	Push       string   `mapstructure:"push"`
+	Pop        bool     `mapstructure:"pop"`
+}
+
+// Load reads a Spec from a configuration file, the format being derived from
+// its extension.
+func Load(path string) (*Spec, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return fromViper(v)
+}
+
+// Read reads a Spec in the given format ("yaml", "json", ...) from r.
+func Read(r io.Reader, format string) (*Spec, error) {
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(r); err != nil {
+		return nil, err
+	}
+	return fromViper(v)
+}
+
+func fromViper(v *viper.Viper) (*Spec, error) {
+	spec := &Spec{}
+	if err := v.Unmarshal(spec); err != nil {
+		return nil, err
+	}
+	if spec.InitialMode == "" {
+		spec.InitialMode = "default"
+	}
+	if spec.EOF == "" || spec.Error == "" {
+		return nil, fmt.Errorf("eof and error token types are required")
+	}
+	modes := map[string]bool{spec.InitialMode: true}
+	for _, rule := range spec.Tokens {
+		for _, mode := range rule.Modes {
+			modes[mode] = true
+		}
+	}
+	for i, rule := range spec.Tokens {
+		if rule.Push != "" && !modes[rule.Push] {
+			return nil, fmt.Errorf("token %d (%s): push to unknown mode %q", i, rule.Type, rule.Push)
+		}
+	}
+	return spec, nil
+}
+
+// Consumers builds the TokenConsumers of the initial mode, ready to be passed to
+// lexer.LexStatic.
+func (s *Spec) Consumers() ([]lexer.TokenConsumer, error) {
+	var consumers []lexer.TokenConsumer
+	for i, rule := range s.Tokens {
+		if !rule.activeIn(s.InitialMode, s.InitialMode) {
+			continue
+		}
+		consumer, err := rule.Consumer()
+		if err != nil {
+			return nil, fmt.Errorf("token %d (%s): %w", i, rule.Type, err)
+		}
+		consumers = append(consumers, consumer)
+	}
+	return consumers, nil
+}
+
+// Lexer builds a ModalLexer with all modes of the spec.
+func (s *Spec) Lexer() (*lexer.ModalLexer, error) {
+	l := lexer.NewModalLexer(s.InitialMode, lexer.TokenType(s.EOF), lexer.TokenType(s.Error))
+	for i, rule := range s.Tokens {
+		consumer, err := rule.Consumer()
+		if err != nil {
+			return nil, fmt.Errorf("token %d (%s): %w", i, rule.Type, err)
+		}
+		modes := rule.Modes
+		if len(modes) == 0 {
+			modes = []string{s.InitialMode}
+		}
+		for _, mode := range modes {
+			l.Mode(mode, lexer.ModeRule{Consumer: consumer, Push: rule.Push, Pop: rule.Pop})
+		}
+	}
+	return l, nil
+}
+
+// Consumer builds the TokenConsumer described by the rule.
+func (s *Rule) Consumer() (lexer.TokenConsumer, error) {
+	if s.Type == "" {
+		return nil, fmt.Errorf("missing token type")
+	}
+	typ := lexer.TokenType(s.Type)
+	var consumer lexer.TokenConsumer
+	switch strings.ToLower(s.Kind) {
+	case "rune":
+		if s.Runes == "" {
+			return nil, fmt.Errorf("kind rune requires runes")
+		}
+		consumer = lexer.ConsumeSingleRune(typ, []rune(s.Runes)...)
+	case "runes":
+		if s.Runes == "" {
+			return nil, fmt.Errorf("kind runes requires runes")
+		}
+		consumer = lexer.ConsumeRunes(typ, s.Runes)
+	case "class":
+		tables, err := rangeTables(s.Classes)
+		if err != nil {
+			return nil, err
+		}
+		consumer = lexer.ConsumeCharacterClass(typ, tables...)
+	case "text":
+		if s.Text == "" {
+			return nil, fmt.Errorf("kind text requires text")
+		}
+		consumer = lexer.ConsumeText(typ, s.Text)
+	case "regex":
+		re, err := regexp.Compile(s.Regex)
+		if err != nil {
+			return nil, err
+		}
+		consumer = lexer.ConsumeRegex(typ, re)
+	case "string":
+		delimiters := s.Delimiters
+		if delimiters == "" {
+			delimiters = "\"'"
+		}
+		consumer = lexer.ConsumeDelimitedString(typ, delimiters)
+	default:
+		return nil, fmt.Errorf("unknown kind %q", s.Kind)
+	}
+	if s.Validate != "" {
+		re, err := regexp.Compile(s.Validate)
+		if err != nil {
+			return nil, err
+		}
+		consumer = lexer.ConsumeRegexpValidated(re, consumer)
+	}
+	return consumer, nil
+}
+
+func (s *Rule) activeIn(mode, initialMode string) bool {
+	if len(s.Modes) == 0 {
+		return mode == initialMode
+	}
+	for _, m := range s.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeTables resolves names of unicode categories ("Lu"), scripts ("Greek")
+// and properties ("White_Space").
+func rangeTables(names []string) ([]*unicode.RangeTable, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("kind class requires classes")
+	}
+	tables := make([]*unicode.RangeTable, 0, len(names))
+	for _, name := range names {
+		table, ok := unicode.Categories[name]
+		if !ok {
+			table, ok = unicode.Scripts[name]
+		}
+		if !ok {
+			table, ok = unicode.Properties[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown unicode class %q", name)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
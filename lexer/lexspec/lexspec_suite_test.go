@@ -0,0 +1,13 @@
+package lexspec
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLexspec(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lexspec Suite")
+}
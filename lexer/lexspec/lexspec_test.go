@@ -0,0 +1,102 @@
+package lexspec
+
+import (
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type recorder struct {
+	tokens []lexer.Token
+}
+
+func (s *recorder) visit(tok lexer.Token) {
+	s.tokens = append(s.tokens, tok)
+}
+
+var _ = Describe("Spec", func() {
+	It("loads a YAML file", func() {
+		spec, err := Load("testdata/sexp.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spec.EOF).To(Equal("EOF"))
+		Expect(spec.InitialMode).To(Equal("default"))
+		Expect(spec.Tokens).To(HaveLen(9))
+		Expect(spec.Tokens[3].Validate).To(Equal(`^(0|0\.\d+|[1-9]\d*(\.\d+)?)$`))
+		Expect(spec.Tokens[5].Classes).To(Equal([]string{"White_Space"}))
+	})
+	It("reads JSON", func() {
+		spec, err := Read(strings.NewReader(`{"eof": "EOF", "error": "ERR", "initialMode": "m", "tokens": [{"type": "A", "kind": "text", "text": "a"}]}`), "json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spec.InitialMode).To(Equal("m"))
+		Expect(spec.Tokens).To(Equal([]Rule{{Type: "A", Kind: "text", Text: "a"}}))
+	})
+	It("requires eof and error token types", func() {
+		_, err := Read(strings.NewReader(`tokens: []`), "yaml")
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects pushes to unknown modes", func() {
+		_, err := Read(strings.NewReader(`{"eof": "EOF", "error": "ERR", "tokens": [{"type": "A", "kind": "text", "text": "a", "push": "nowhere"}]}`), "json")
+		Expect(err).To(MatchError(ContainSubstring(`unknown mode "nowhere"`)))
+	})
+	It("builds the consumers of the initial mode", func() {
+		spec, _ := Load("testdata/sexp.yaml")
+		consumers, err := spec.Consumers()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(consumers).To(HaveLen(7))
+		rv := recorder{}
+		lexer.LexStatic(lexer.StringReader("(a 1.5 'b')"), rv.visit, "EOF", "ERR", consumers...)
+		Expect(rv.tokens).To(Equal(lexer.NewTokenGenerator().
+			T("START", "(").
+			T("SYMBOL", "a").
+			T("WS", " ").
+			T("NUMBER", "1.5").
+			T("WS", " ").
+			T("STRING", "'b'").
+			T("END", ")").
+			T("EOF", "").
+			Build()))
+	})
+	It("builds a modal lexer", func() {
+		spec, _ := Load("testdata/sexp.yaml")
+		l, err := spec.Lexer()
+		Expect(err).ToNot(HaveOccurred())
+		rv := recorder{}
+		l.Lex(lexer.StringReader("(#|some text|#)"), rv.visit)
+		Expect(rv.tokens).To(Equal(lexer.NewTokenGenerator().
+			T("START", "(").
+			T("COMMENT_START", "#|").
+			T("COMMENT", "some text").
+			T("COMMENT_END", "|#").
+			T("END", ")").
+			T("EOF", "").
+			Build()))
+	})
+})
+
+var _ = Describe("Rule", func() {
+	It("rejects unknown kinds", func() {
+		_, err := (&Rule{Type: "A", Kind: "magic"}).Consumer()
+		Expect(err).To(MatchError(ContainSubstring("unknown kind")))
+	})
+	It("rejects unknown unicode classes", func() {
+		_, err := (&Rule{Type: "A", Kind: "class", Classes: []string{"Klingon"}}).Consumer()
+		Expect(err).To(MatchError(ContainSubstring("unknown unicode class")))
+	})
+	It("rejects invalid regular expressions", func() {
+		_, err := (&Rule{Type: "A", Kind: "regex", Regex: "a("}).Consumer()
+		Expect(err).To(HaveOccurred())
+	})
+	It("rejects rules without token type", func() {
+		_, err := (&Rule{Kind: "text", Text: "a"}).Consumer()
+		Expect(err).To(HaveOccurred())
+	})
+	It("builds string consumers with custom delimiters", func() {
+		consumer, err := (&Rule{Type: "S", Kind: "string", Delimiters: "`"}).Consumer()
+		Expect(err).ToNot(HaveOccurred())
+		tok, valid := consumer(lexer.StringReader("`a`"))
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("`a`"))
+	})
+})
@@ -0,0 +1,40 @@
+// Package literals provides consumers for typed literals commonly embedded in
+// configuration and query languages. Every consumer emits the raw lexeme as
+// Value and the parsed Go value as Data, and does not match if the lexeme has
+// the right shape but does not parse.
+package literals
+
+import (
+	"github.com/mtrense/parsertk/lexer"
+)
+
+// literal matches the longest prefix of the input with the shape of pattern and
+// accepts it if parse succeeds.
+func literal(typ lexer.TokenType, pattern string, parse func(string) (interface{}, error)) lexer.TokenConsumer {
+	shape := lexer.MustCompileRegexSet(lexer.Regex(typ, pattern)).Consumer()
+	return func(input lexer.BufferedRuneReader) (lexer.Token, bool) {
+		mark := input.Mark()
+		tok, valid := shape(input)
+		if valid {
+			if value, err := parse(tok.Value); err == nil {
+				mark.Commit()
+				tok.Data = value
+				return tok, true
+			}
+		}
+		mark.Rollback()
+		return lexer.Token{}, false
+	}
+}
+
+// firstOf returns the token of the first consumer that matches.
+func firstOf(consumers ...lexer.TokenConsumer) lexer.TokenConsumer {
+	return func(input lexer.BufferedRuneReader) (lexer.Token, bool) {
+		for _, consumer := range consumers {
+			if tok, valid := consumer(input); valid {
+				return tok, true
+			}
+		}
+		return lexer.Token{}, false
+	}
+}
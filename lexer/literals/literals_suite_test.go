@@ -0,0 +1,13 @@
+package literals
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLiterals(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Literals Suite")
+}
@@ -0,0 +1,40 @@
+package literals
+
+import (
+	"time"
+
+	"github.com/mtrense/parsertk/lexer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const TokenTypeLiteral lexer.TokenType = "LITERAL"
+
+// consume runs consumer on input and returns the token, whether it matched and
+// the offset the reader stopped at.
+func consume(consumer lexer.TokenConsumer, input string) (lexer.Token, bool, int) {
+	reader := lexer.StringReader(input)
+	tok, valid := consumer(reader)
+	return tok, valid, reader.Offset()
+}
+
+var _ = Describe("literals", func() {
+	It("lex typed literals in a token stream", func() {
+		var tokens []lexer.Token
+		lexer.LexStatic(lexer.StringReader("2024-02-29 10.0.0.0/8 1h30m v1.2.3"), func(tok lexer.Token) {
+			tokens = append(tokens, tok)
+		}, "EOF", "ERR",
+			ConsumeDate("DATE"),
+			ConsumeCIDR("CIDR"),
+			ConsumeDuration("DURATION"),
+			ConsumeSemVer("VERSION"),
+			lexer.ConsumeRunes("WS", " "),
+		)
+		types := make([]lexer.TokenType, len(tokens))
+		for i, tok := range tokens {
+			types[i] = tok.Typ
+		}
+		Expect(types).To(Equal([]lexer.TokenType{"DATE", "WS", "CIDR", "WS", "DURATION", "WS", "VERSION", "EOF"}))
+		Expect(tokens[4].Data).To(Equal(90 * time.Minute))
+	})
+})
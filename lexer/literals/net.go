@@ -0,0 +1,72 @@
+package literals
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const (
+	ipv4Pattern = `[0-9]{1,3}(\.[0-9]{1,3}){3}`
+	ipv6Pattern = `[0-9A-Fa-f:]*:[0-9A-Fa-f:]*(` + ipv4Pattern + `)?`
+	urlPattern  = `[A-Za-z][A-Za-z0-9+.\-]*://[^\s"'<>` + "`" + `]+`
+)
+
+var errInvalid = errors.New("invalid literal")
+
+// CIDR is the value of a token consumed by ConsumeCIDR.
+type CIDR struct {
+	IP      net.IP
+	Network *net.IPNet
+}
+
+// ConsumeIPv4 consumes a dotted decimal IPv4 address. Data holds a net.IP.
+func ConsumeIPv4(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, ipv4Pattern, parseIP)
+}
+
+// ConsumeIPv6 consumes an IPv6 address, such as ::1 or ::ffff:192.0.2.1. Data
+// holds a net.IP.
+func ConsumeIPv6(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, ipv6Pattern, parseIP)
+}
+
+// ConsumeIP consumes an IPv6 or IPv4 address. Data holds a net.IP.
+func ConsumeIP(typ lexer.TokenType) lexer.TokenConsumer {
+	return firstOf(ConsumeIPv6(typ), ConsumeIPv4(typ))
+}
+
+// ConsumeCIDR consumes an IPv4 or IPv6 address with prefix length, such as
+// 192.0.2.1/24. Data holds a CIDR.
+func ConsumeCIDR(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, `(`+ipv4Pattern+`|`+ipv6Pattern+`)/[0-9]{1,3}`, func(lexeme string) (interface{}, error) {
+		ip, network, err := net.ParseCIDR(lexeme)
+		if err != nil {
+			return nil, err
+		}
+		return CIDR{IP: ip, Network: network}, nil
+	})
+}
+
+// ConsumeURL consumes an absolute URL with a scheme followed by ://, such as
+// https://example.com/path?q=1. The URL extends up to the next whitespace,
+// quote, backtick or angle bracket. Data holds a *url.URL.
+func ConsumeURL(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, urlPattern, func(lexeme string) (interface{}, error) {
+		return url.Parse(lexeme)
+	})
+}
+
+func parseIP(lexeme string) (interface{}, error) {
+	ip := net.ParseIP(lexeme)
+	if ip == nil {
+		return nil, errInvalid
+	}
+	if strings.Contains(lexeme, ":") {
+		return ip, nil
+	}
+	return ip.To4(), nil
+}
@@ -0,0 +1,87 @@
+package literals
+
+import (
+	"net"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConsumeIPv4", func() {
+	It("parses addresses", func() {
+		tok, valid, _ := consume(ConsumeIPv4(TokenTypeLiteral), "192.0.2.1:80")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("192.0.2.1"))
+		Expect(tok.Data).To(Equal(net.IPv4(192, 0, 2, 1).To4()))
+	})
+	It("rejects octets out of range", func() {
+		_, valid, _ := consume(ConsumeIPv4(TokenTypeLiteral), "192.0.2.256")
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeIPv6", func() {
+	It("parses addresses", func() {
+		for _, lexeme := range []string{"::1", "2001:db8::ff00:42:8329", "::ffff:192.0.2.1"} {
+			tok, valid, _ := consume(ConsumeIPv6(TokenTypeLiteral), lexeme+" ")
+			Expect(valid).To(BeTrue())
+			Expect(tok.Value).To(Equal(lexeme))
+			Expect(tok.Data).To(Equal(net.ParseIP(lexeme)))
+		}
+	})
+	It("rejects malformed addresses", func() {
+		_, valid, offset := consume(ConsumeIPv6(TokenTypeLiteral), "12:30")
+		Expect(valid).To(BeFalse())
+		Expect(offset).To(BeZero())
+	})
+})
+
+var _ = Describe("ConsumeIP", func() {
+	It("parses both address families", func() {
+		tok, valid, _ := consume(ConsumeIP(TokenTypeLiteral), "10.1.2.3")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(HaveLen(net.IPv4len))
+		tok, valid, _ = consume(ConsumeIP(TokenTypeLiteral), "fe80::1")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Data).To(HaveLen(net.IPv6len))
+	})
+})
+
+var _ = Describe("ConsumeCIDR", func() {
+	It("parses networks", func() {
+		tok, valid, _ := consume(ConsumeCIDR(TokenTypeLiteral), "192.0.2.17/24,")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("192.0.2.17/24"))
+		cidr := tok.Data.(CIDR)
+		Expect(cidr.IP.String()).To(Equal("192.0.2.17"))
+		Expect(cidr.Network.String()).To(Equal("192.0.2.0/24"))
+		tok, _, _ = consume(ConsumeCIDR(TokenTypeLiteral), "2001:db8::/32")
+		Expect(tok.Data.(CIDR).Network.String()).To(Equal("2001:db8::/32"))
+	})
+	It("rejects invalid prefix lengths", func() {
+		_, valid, _ := consume(ConsumeCIDR(TokenTypeLiteral), "192.0.2.1/33")
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeURL", func() {
+	It("parses URLs", func() {
+		tok, valid, _ := consume(ConsumeURL(TokenTypeLiteral), `https://example.com:8080/a/b?q=1#top" rest`)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("https://example.com:8080/a/b?q=1#top"))
+		u := tok.Data.(*url.URL)
+		Expect(u.Scheme).To(Equal("https"))
+		Expect(u.Host).To(Equal("example.com:8080"))
+		Expect(u.Path).To(Equal("/a/b"))
+		Expect(u.Fragment).To(Equal("top"))
+	})
+	It("does not match without scheme", func() {
+		_, valid, _ := consume(ConsumeURL(TokenTypeLiteral), "example.com/path")
+		Expect(valid).To(BeFalse())
+	})
+	It("rejects malformed URLs", func() {
+		_, valid, _ := consume(ConsumeURL(TokenTypeLiteral), "http://[::1/")
+		Expect(valid).To(BeFalse())
+	})
+})
@@ -0,0 +1,121 @@
+package literals
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const (
+	semverNumber     = `(0|[1-9][0-9]*)`
+	semverIdentifier = `[0-9A-Za-z\-]+`
+	semverPattern    = `v?` + semverNumber + `\.` + semverNumber + `\.` + semverNumber +
+		`(-` + semverIdentifier + `(\.` + semverIdentifier + `)*)?` +
+		`(\+` + semverIdentifier + `(\.` + semverIdentifier + `)*)?`
+)
+
+// Version is the value of a token consumed by ConsumeSemVer.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// String returns the version in semver syntax, without leading v.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 depending on whether v has lower, equal or higher
+// precedence than other. Build metadata is ignored.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]uint64{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return compareUint(pair[0], pair[1])
+		}
+	}
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ConsumeSemVer consumes a semantic version as specified by semver.org 2.0.0,
+// optionally prefixed with v, such as v1.2.3-rc.1+build.5. Data holds a
+// Version.
+func ConsumeSemVer(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, semverPattern, func(lexeme string) (interface{}, error) {
+		return parseVersion(strings.TrimPrefix(lexeme, "v"))
+	})
+}
+
+func parseVersion(s string) (Version, error) {
+	var v Version
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		v.Build = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.Prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+		for _, identifier := range v.Prerelease {
+			if len(identifier) > 1 && identifier[0] == '0' && strings.Trim(identifier, "0123456789") == "" {
+				return Version{}, fmt.Errorf("numeric identifier %q has leading zeros", identifier)
+			}
+		}
+	}
+	numbers := strings.Split(s, ".")
+	for i, field := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		n, err := strconv.ParseUint(numbers[i], 10, 64)
+		if err != nil {
+			return Version{}, err
+		}
+		*field = n
+	}
+	return v, nil
+}
@@ -0,0 +1,39 @@
+package literals
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func version(lexeme string) Version {
+	tok, valid, _ := consume(ConsumeSemVer(TokenTypeLiteral), lexeme)
+	Expect(valid).To(BeTrue())
+	return tok.Data.(Version)
+}
+
+var _ = Describe("ConsumeSemVer", func() {
+	It("parses versions", func() {
+		tok, valid, _ := consume(ConsumeSemVer(TokenTypeLiteral), "v1.20.3-rc.1+build.5 ")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("v1.20.3-rc.1+build.5"))
+		Expect(tok.Data).To(Equal(Version{Major: 1, Minor: 20, Patch: 3, Prerelease: []string{"rc", "1"}, Build: []string{"build", "5"}}))
+		Expect(tok.Data.(Version).String()).To(Equal("1.20.3-rc.1+build.5"))
+	})
+	It("parses plain versions", func() {
+		Expect(version("0.1.0")).To(Equal(Version{Minor: 1}))
+	})
+	It("rejects leading zeros", func() {
+		_, valid, _ := consume(ConsumeSemVer(TokenTypeLiteral), "1.2.3-01")
+		Expect(valid).To(BeFalse())
+		_, valid, _ = consume(ConsumeSemVer(TokenTypeLiteral), "01.2.3")
+		Expect(valid).To(BeFalse())
+	})
+	It("orders versions by precedence", func() {
+		ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+		for i := 1; i < len(ordered); i++ {
+			Expect(version(ordered[i-1]).Compare(version(ordered[i]))).To(Equal(-1), ordered[i])
+			Expect(version(ordered[i]).Compare(version(ordered[i-1]))).To(Equal(1), ordered[i])
+		}
+		Expect(version("1.0.0+a").Compare(version("1.0.0+b"))).To(BeZero())
+	})
+})
@@ -0,0 +1,61 @@
+package literals
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const (
+	datePattern     = `[0-9]{4}-[0-9]{2}-[0-9]{2}`
+	timePattern     = `[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?`
+	zonePattern     = `(Z|z|[+\-][0-9]{2}:[0-9]{2})`
+	durationUnit    = `([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h)`
+	durationPattern = `[+\-]?` + durationUnit + `(` + durationUnit + `)*`
+)
+
+// ConsumeDate consumes an ISO-8601 calendar date such as 2006-01-02. Data holds
+// a time.Time at midnight UTC.
+func ConsumeDate(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, datePattern, func(lexeme string) (interface{}, error) {
+		return time.Parse("2006-01-02", lexeme)
+	})
+}
+
+// ConsumeTime consumes an ISO-8601 time of day such as 15:04:05 or
+// 15:04:05.999, without zone. Data holds a time.Time on January 1, year 0, UTC,
+// as returned by time.Parse.
+func ConsumeTime(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, timePattern, func(lexeme string) (interface{}, error) {
+		return time.Parse("15:04:05", lexeme)
+	})
+}
+
+// ConsumeDateTime consumes an ISO-8601 date and time separated by T or a
+// space, with optional fractional seconds and zone, such as
+// 2006-01-02T15:04:05.999+07:00. Data holds a time.Time, in UTC if the zone is
+// missing.
+func ConsumeDateTime(typ lexer.TokenType) lexer.TokenConsumer {
+	pattern := datePattern + `[Tt ]` + timePattern + zonePattern + `?`
+	return literal(typ, pattern, func(lexeme string) (interface{}, error) {
+		normalized := []byte(lexeme)
+		normalized[10] = 'T'
+		if last := len(normalized) - 1; normalized[last] == 'z' {
+			normalized[last] = 'Z'
+		}
+		layout := time.RFC3339Nano
+		if !strings.ContainsAny(string(normalized[19:]), "Z+-") {
+			layout = "2006-01-02T15:04:05"
+		}
+		return time.Parse(layout, string(normalized))
+	})
+}
+
+// ConsumeDuration consumes a duration in the syntax of time.ParseDuration, such
+// as 1h30m or -1.5s. Data holds a time.Duration.
+func ConsumeDuration(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, durationPattern, func(lexeme string) (interface{}, error) {
+		return time.ParseDuration(lexeme)
+	})
+}
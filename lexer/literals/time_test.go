@@ -0,0 +1,77 @@
+package literals
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConsumeDate", func() {
+	It("parses calendar dates", func() {
+		tok, valid, offset := consume(ConsumeDate(TokenTypeLiteral), "2024-02-29;")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("2024-02-29"))
+		Expect(tok.Data).To(Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)))
+		Expect(offset).To(Equal(10))
+	})
+	It("rejects impossible dates", func() {
+		_, valid, offset := consume(ConsumeDate(TokenTypeLiteral), "2023-02-29")
+		Expect(valid).To(BeFalse())
+		Expect(offset).To(BeZero())
+	})
+})
+
+var _ = Describe("ConsumeTime", func() {
+	It("parses times of day", func() {
+		tok, valid, _ := consume(ConsumeTime(TokenTypeLiteral), "23:59:01.25 ")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("23:59:01.25"))
+		Expect(tok.Data).To(Equal(time.Date(0, 1, 1, 23, 59, 1, 250000000, time.UTC)))
+	})
+	It("rejects invalid times", func() {
+		_, valid, _ := consume(ConsumeTime(TokenTypeLiteral), "24:00:00")
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeDateTime", func() {
+	It("parses date times with zone", func() {
+		tok, valid, _ := consume(ConsumeDateTime(TokenTypeLiteral), "2006-01-02T15:04:05.5+07:00 x")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("2006-01-02T15:04:05.5+07:00"))
+		Expect(tok.Data.(time.Time).Equal(time.Date(2006, 1, 2, 8, 4, 5, 500000000, time.UTC))).To(BeTrue())
+	})
+	It("parses UTC date times", func() {
+		tok, _, _ := consume(ConsumeDateTime(TokenTypeLiteral), "2006-01-02t15:04:05z")
+		Expect(tok.Data).To(Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)))
+	})
+	It("parses date times without zone", func() {
+		tok, _, _ := consume(ConsumeDateTime(TokenTypeLiteral), "2006-01-02 15:04:05")
+		Expect(tok.Data).To(Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)))
+	})
+	It("does not match dates alone", func() {
+		_, valid, _ := consume(ConsumeDateTime(TokenTypeLiteral), "2006-01-02")
+		Expect(valid).To(BeFalse())
+	})
+})
+
+var _ = Describe("ConsumeDuration", func() {
+	It("parses durations", func() {
+		for lexeme, expected := range map[string]time.Duration{
+			"1h30m":   90 * time.Minute,
+			"-1.5s":   -1500 * time.Millisecond,
+			"250ms":   250 * time.Millisecond,
+			"2µs10ns": 2010 * time.Nanosecond,
+		} {
+			tok, valid, _ := consume(ConsumeDuration(TokenTypeLiteral), lexeme+" ")
+			Expect(valid).To(BeTrue())
+			Expect(tok.Value).To(Equal(lexeme))
+			Expect(tok.Data).To(Equal(expected))
+		}
+	})
+	It("does not match numbers without unit", func() {
+		_, valid, _ := consume(ConsumeDuration(TokenTypeLiteral), "15")
+		Expect(valid).To(BeFalse())
+	})
+})
@@ -0,0 +1,29 @@
+package literals
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const uuidPattern = `[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}`
+
+// UUID is the value of a token consumed by ConsumeUUID.
+type UUID [16]byte
+
+// String returns the canonical lower case form of the UUID.
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// ConsumeUUID consumes a UUID in its hyphenated form, in either case. Data
+// holds a UUID.
+func ConsumeUUID(typ lexer.TokenType) lexer.TokenConsumer {
+	return literal(typ, uuidPattern, func(lexeme string) (interface{}, error) {
+		var u UUID
+		_, err := hex.Decode(u[:], []byte(strings.Replace(lexeme, "-", "", -1)))
+		return u, err
+	})
+}
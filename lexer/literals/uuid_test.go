@@ -0,0 +1,20 @@
+package literals
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConsumeUUID", func() {
+	It("parses UUIDs in either case", func() {
+		tok, valid, _ := consume(ConsumeUUID(TokenTypeLiteral), "123E4567-e89b-12d3-A456-426614174000}")
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("123E4567-e89b-12d3-A456-426614174000"))
+		Expect(tok.Data).To(Equal(UUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}))
+		Expect(tok.Data.(UUID).String()).To(Equal("123e4567-e89b-12d3-a456-426614174000"))
+	})
+	It("does not match truncated UUIDs", func() {
+		_, valid, _ := consume(ConsumeUUID(TokenTypeLiteral), "123e4567-e89b-12d3-a456-42661417400")
+		Expect(valid).To(BeFalse())
+	})
+})
@@ -0,0 +1,124 @@
+package lexer
+
+// Mark is a position remembered by a BufferedRuneReader. A mark is released
+// either by Commit, which keeps the current position, or by Rollback, which
+// returns to the marked position. Marks nest: releasing a mark also releases all
+// marks taken after it. Releasing a mark that has already been released is a
+// no-op.
+type Mark struct {
+	stack  *MarkStack
+	depth  int
+	serial uint64
+	offset int
+}
+
+// Offset returns the offset the mark was taken at.
+func (m Mark) Offset() int {
+	return m.offset
+}
+
+// Commit discards the mark and all marks taken after it, keeping the position.
+func (m Mark) Commit() {
+	if m.Active() {
+		m.stack.entries = m.stack.entries[:m.depth]
+	}
+}
+
+// Rollback returns to the marked position and discards the mark and all marks
+// taken after it.
+func (m Mark) Rollback() {
+	if m.Active() {
+		entry := m.stack.entries[m.depth]
+		m.stack.entries = m.stack.entries[:m.depth]
+		m.stack.restore(entry.offset, entry.pos)
+	}
+}
+
+// Active reports whether the mark has not been released yet.
+func (m Mark) Active() bool {
+	return m.stack != nil && m.depth < len(m.stack.entries) && m.stack.entries[m.depth].serial == m.serial
+}
+
+type markEntry struct {
+	serial uint64
+	offset int
+	pos    int
+}
+
+// MarkStack keeps the nested marks of a reader. Next to the offset reported to
+// consumers every mark records a reader specific position, such as an index
+// into a byte buffer, that is handed back to restore on rollback.
+type MarkStack struct {
+	entries []markEntry
+	serial  uint64
+	restore func(offset, pos int)
+}
+
+func NewMarkStack(restore func(offset, pos int)) *MarkStack {
+	return &MarkStack{
+		entries: make([]markEntry, 0),
+		restore: restore,
+	}
+}
+
+// Push takes a new innermost mark.
+func (s *MarkStack) Push(offset, pos int) Mark {
+	s.serial++
+	s.entries = append(s.entries, markEntry{serial: s.serial, offset: offset, pos: pos})
+	return Mark{
+		stack:  s,
+		depth:  len(s.entries) - 1,
+		serial: s.serial,
+		offset: offset,
+	}
+}
+
+// Rewind rolls back the innermost mark, if any.
+func (s *MarkStack) Rewind() {
+	if len(s.entries) > 0 {
+		s.Innermost().Rollback()
+	}
+}
+
+// Innermost returns the most recently taken active mark. It returns an inactive
+// Mark if there is none.
+func (s *MarkStack) Innermost() Mark {
+	if len(s.entries) == 0 {
+		return Mark{}
+	}
+	entry := s.entries[len(s.entries)-1]
+	return Mark{
+		stack:  s,
+		depth:  len(s.entries) - 1,
+		serial: entry.serial,
+		offset: entry.offset,
+	}
+}
+
+// Len returns the number of active marks.
+func (s *MarkStack) Len() int {
+	return len(s.entries)
+}
+
+// Oldest returns the reader specific position of the outermost active mark.
+// Streaming readers must retain all data from there on.
+func (s *MarkStack) Oldest() (pos int, ok bool) {
+	if len(s.entries) == 0 {
+		return 0, false
+	}
+	return s.entries[0].pos, true
+}
+
+// transactional makes consumer leave the reader untouched when it does not match.
+func transactional(consumer TokenConsumer) TokenConsumer {
+	return func(input BufferedRuneReader) (Token, bool) {
+		mark := input.Mark()
+		tok, valid := consumer(input)
+		if valid {
+			mark.Commit()
+		} else {
+			mark.Rollback()
+		}
+		return tok, valid
+	}
+}
@@ -0,0 +1,91 @@
+package lexer
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mark", func() {
+	It("rolls back to the marked offset", func() {
+		s := StringReader("abcdef")
+		s.Read()
+		mark := s.Mark()
+		Expect(mark.Offset()).To(Equal(1))
+		s.Read()
+		s.Read()
+		mark.Rollback()
+		Expect(s.Offset()).To(Equal(1))
+		Expect(s.(*stringReader).marks.Len()).To(Equal(0))
+	})
+	It("keeps the position on commit", func() {
+		s := StringReader("abcdef")
+		mark := s.Mark()
+		s.Read()
+		s.Read()
+		mark.Commit()
+		Expect(s.Offset()).To(Equal(2))
+		Expect(s.(*stringReader).marks.Len()).To(Equal(0))
+	})
+	It("releases inner marks together with outer ones", func() {
+		s := StringReader("abcdef")
+		outer := s.Mark()
+		s.Read()
+		inner := s.Mark()
+		s.Read()
+		outer.Rollback()
+		Expect(s.Offset()).To(Equal(0))
+		Expect(inner.Active()).To(BeFalse())
+		Expect(s.(*stringReader).marks.Len()).To(Equal(0))
+	})
+	It("keeps outer marks when inner ones are released", func() {
+		s := StringReader("abcdef")
+		outer := s.Mark()
+		s.Read()
+		inner := s.Mark()
+		s.Read()
+		inner.Commit()
+		Expect(outer.Active()).To(BeTrue())
+		s.Read()
+		outer.Rollback()
+		Expect(s.Offset()).To(Equal(0))
+	})
+	It("ignores releases of released marks", func() {
+		s := StringReader("abcdef")
+		stale := s.Mark()
+		s.Read()
+		stale.Commit()
+		fresh := s.Mark()
+		s.Read()
+		stale.Rollback()
+		Expect(s.Offset()).To(Equal(2))
+		Expect(fresh.Active()).To(BeTrue())
+	})
+	It("rewinds the innermost mark", func() {
+		s := SourceStringReader("äbcdef")
+		s.Read()
+		s.Mark()
+		s.Read()
+		s.Rewind()
+		Expect(s.Offset()).To(Equal(1))
+		Expect(s.Read()).To(Equal('b'))
+	})
+})
+
+var (
+	regexpAbbbc = regexp.MustCompile("ab+c")
+	regexpNever = regexp.MustCompile("^$")
+)
+
+var _ = Describe("Built-in consumers", func() {
+	It("leave the reader untouched when they do not match", func() {
+		for _, consumer := range append(SexpTokens, ConsumeText(TokenTypeSymbol, "abd"), ConsumeRegex(TokenTypeSymbol, regexpAbbbc)) {
+			s := StringReader("abc\"def")
+			_, valid := ConsumeRegexpValidated(regexpNever, consumer)(s)
+			Expect(valid).To(BeFalse())
+			Expect(s.Offset()).To(Equal(0))
+			Expect(s.(*stringReader).marks.Len()).To(Equal(0))
+		}
+	})
+})
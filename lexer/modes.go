@@ -0,0 +1,132 @@
+package lexer
+
+import (
+	"strings"
+)
+
+// ModeRule is a TokenConsumer that is active in one mode of a ModalLexer. After
+// the consumer matched, the current mode is popped if Pop is set and Push is
+// entered if given.
+type ModeRule struct {
+	Consumer TokenConsumer
+	Push     string
+	Pop      bool
+}
+
+// ModeRules wraps consumers into ModeRules that do not change the mode.
+func ModeRules(consumers ...TokenConsumer) []ModeRule {
+	rules := make([]ModeRule, len(consumers))
+	for i, consumer := range consumers {
+		rules[i] = ModeRule{Consumer: consumer}
+	}
+	return rules
+}
+
+// ModalLexer scans the input with a stack of modes, each having its own set of
+// valid Tokens.
+type ModalLexer struct {
+	modes       map[string][]ModeRule
+	initialMode string
+	eofToken    TokenType
+	errorToken  TokenType
+}
+
+func NewModalLexer(initialMode string, eofToken, errorToken TokenType) *ModalLexer {
+	return &ModalLexer{
+		modes:       make(map[string][]ModeRule),
+		initialMode: initialMode,
+		eofToken:    eofToken,
+		errorToken:  errorToken,
+	}
+}
+
+// Mode appends rules to the named mode.
+func (s *ModalLexer) Mode(name string, rules ...ModeRule) *ModalLexer {
+	s.modes[name] = append(s.modes[name], rules...)
+	return s
+}
+
+// Rules returns the rules of the named mode.
+func (s *ModalLexer) Rules(mode string) []ModeRule {
+	return s.modes[mode]
+}
+
+// Lex scans the input starting in the initial mode. Pops on the initial mode
+// are ignored.
+func (s *ModalLexer) Lex(input BufferedRuneReader, visitor Visitor) {
+	scanner := s.Scanner(input, s.InitialState())
+	for {
+		tok, ok := scanner.Next()
+		if !ok {
+			break
+		}
+		visitor(tok)
+	}
+}
+
+// State is an opaque snapshot of everything a ModalLexer carries from one token
+// to the next, which is its mode stack. States are comparable, so a highlighter
+// that caches the State at every line start can stop re-lexing after an edit
+// as soon as it reaches a line whose State did not change.
+type State struct {
+	modes string
+}
+
+const stateSeparator = "\x00"
+
+// InitialState returns the State lexing starts in.
+func (s *ModalLexer) InitialState() State {
+	return State{modes: s.initialMode}
+}
+
+// Scanner returns a Scanner that lexes input starting in state. To resume at an
+// arbitrary offset, position the input there, e.g. with StringReaderFrom.
+func (s *ModalLexer) Scanner(input BufferedRuneReader, state State) *Scanner {
+	return &Scanner{
+		lexer: s,
+		input: input,
+		modes: strings.Split(state.modes, stateSeparator),
+	}
+}
+
+// Scanner lexes one token at a time, exposing the State between tokens.
+type Scanner struct {
+	lexer    *ModalLexer
+	input    BufferedRuneReader
+	modes    []string
+	finished bool
+}
+
+// State returns the snapshot of the state before the next token.
+func (s *Scanner) State() State {
+	return State{modes: strings.Join(s.modes, stateSeparator)}
+}
+
+// Offset returns the offset of the next token.
+func (s *Scanner) Offset() int {
+	return s.input.Offset()
+}
+
+// Next returns the next token. It returns false after the EOF or error token
+// has been returned.
+func (s *Scanner) Next() (Token, bool) {
+	if s.finished {
+		return Token{}, false
+	}
+	rules := s.lexer.modes[s.modes[len(s.modes)-1]]
+	tok, i := scan(s.input, s.lexer.eofToken, s.lexer.errorToken, len(rules), func(i int) (Token, bool) {
+		return rules[i].Consumer(s.input)
+	})
+	if i < 0 {
+		s.finished = true
+		return tok, true
+	}
+	rule := rules[i]
+	if rule.Pop && len(s.modes) > 1 {
+		s.modes = s.modes[:len(s.modes)-1]
+	}
+	if rule.Push != "" {
+		s.modes = append(s.modes, rule.Push)
+	}
+	return tok, true
+}
@@ -0,0 +1,137 @@
+package lexer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	TokenTypeCommentStart TokenType = "COMMENT_START"
+	TokenTypeComment      TokenType = "COMMENT"
+	TokenTypeCommentEnd   TokenType = "COMMENT_END"
+)
+
+var SexpModalLexer = NewModalLexer("default", TokenTypeEOF, TokenTypeError).
+	Mode("default", ModeRule{Consumer: ConsumeText(TokenTypeCommentStart, "#|"), Push: "comment"}).
+	Mode("default", ModeRules(SexpTokens...)...).
+	Mode("comment",
+		ModeRule{Consumer: ConsumeText(TokenTypeCommentEnd, "|#"), Pop: true},
+		ModeRule{Consumer: ConsumeRunes(TokenTypeComment, "abcdefghijklmnopqrstuvwxyz ()")},
+	)
+
+var _ = Describe("ModalLexer", func() {
+	var rv RecordingVisitor
+	BeforeEach(func() {
+		rv = RecordingVisitor{}
+	})
+	It("tokenizes like LexStatic with a single mode", func() {
+		static := RecordingVisitor{}
+		LexStatic(StringReader("(a \"b\")"), static.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		SexpModalLexer.Lex(StringReader("(a \"b\")"), rv.visit)
+		Expect(rv.tokens).To(Equal(static.tokens))
+	})
+	It("switches between modes", func() {
+		SexpModalLexer.Lex(StringReader("(#|a (b)|#)"), rv.visit)
+		Expect(rv.tokens).To(Equal(NewTokenGenerator().
+			T(TokenTypeStart, "(").
+			T(TokenTypeCommentStart, "#|").
+			T(TokenTypeComment, "a (b)").
+			T(TokenTypeCommentEnd, "|#").
+			T(TokenTypeEnd, ")").
+			T(TokenTypeEOF, "").
+			Build()))
+	})
+	It("only tries the rules of the current mode", func() {
+		SexpModalLexer.Lex(StringReader("#|\"|#"), rv.visit)
+		Expect(rv.tokens).To(Equal(NewTokenGenerator().
+			T(TokenTypeCommentStart, "#|").
+			T(TokenTypeError, "No valid token found").
+			Build()))
+	})
+})
+
+// lineStates lexes source line by line, returning the State at every line start.
+func lineStates(l *ModalLexer, lines []string, from int, state State, cached []State) ([]State, int) {
+	states := append([]State(nil), cached...)
+	for i := from; i < len(lines); i++ {
+		if i < len(states) && i > from && states[i] == state {
+			return states, i
+		}
+		if i < len(states) {
+			states[i] = state
+		} else {
+			states = append(states, state)
+		}
+		scanner := l.Scanner(StringReader(lines[i]), state)
+		for {
+			if _, ok := scanner.Next(); !ok {
+				break
+			}
+		}
+		state = scanner.State()
+	}
+	return states, len(lines)
+}
+
+var _ = Describe("Scanner", func() {
+	It("returns tokens one by one and stops after EOF", func() {
+		scanner := SexpModalLexer.Scanner(StringReader("()"), SexpModalLexer.InitialState())
+		tok, ok := scanner.Next()
+		Expect(ok).To(BeTrue())
+		Expect(tok.Typ).To(Equal(TokenTypeStart))
+		scanner.Next()
+		tok, ok = scanner.Next()
+		Expect(ok).To(BeTrue())
+		Expect(tok.Typ).To(Equal(TokenTypeEOF))
+		_, ok = scanner.Next()
+		Expect(ok).To(BeFalse())
+	})
+	It("exposes comparable states", func() {
+		scanner := SexpModalLexer.Scanner(StringReader("(#|a|#)"), SexpModalLexer.InitialState())
+		Expect(scanner.State()).To(Equal(SexpModalLexer.InitialState()))
+		scanner.Next()
+		scanner.Next()
+		inComment := scanner.State()
+		Expect(inComment == SexpModalLexer.InitialState()).To(BeFalse())
+		scanner.Next()
+		Expect(scanner.State() == inComment).To(BeTrue())
+		scanner.Next()
+		Expect(scanner.State() == SexpModalLexer.InitialState()).To(BeTrue())
+	})
+	It("resumes from a snapshot at an arbitrary offset", func() {
+		input := "(a #|b (c)|# d)"
+		full := RecordingVisitor{}
+		SexpModalLexer.Lex(StringReader(input), full.visit)
+		scanner := SexpModalLexer.Scanner(StringReader(input), SexpModalLexer.InitialState())
+		for scanner.Offset() < 5 {
+			scanner.Next()
+		}
+		state, offset := scanner.State(), scanner.Offset()
+		resumed := SexpModalLexer.Scanner(StringReaderFrom(input, offset), state)
+		var tokens []Token
+		for {
+			tok, ok := resumed.Next()
+			if !ok {
+				break
+			}
+			tokens = append(tokens, tok)
+		}
+		Expect(tokens).To(Equal(full.tokens[len(full.tokens)-len(tokens):]))
+		Expect(tokens[0]).To(Equal(Token{Typ: TokenTypeComment, Value: "b (c)", Offset: 5}))
+	})
+	It("lets a line based highlighter stop once states converge", func() {
+		lines := []string{"(a", "b #|c", "d", "e|# f)", "(g)", "(h)"}
+		states, _ := lineStates(SexpModalLexer, lines, 0, SexpModalLexer.InitialState(), nil)
+		Expect(states[2] == states[3]).To(BeTrue())
+		Expect(states[2] == states[4]).To(BeFalse())
+
+		lines[2] = "d e"
+		_, stoppedAt := lineStates(SexpModalLexer, lines, 2, states[2], states)
+		Expect(stoppedAt).To(Equal(3))
+
+		lines[1] = "b c"
+		updated, stoppedAt := lineStates(SexpModalLexer, lines, 1, states[1], states)
+		Expect(stoppedAt).To(Equal(4))
+		Expect(updated[2] == SexpModalLexer.InitialState()).To(BeTrue())
+	})
+})
@@ -0,0 +1,127 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer/dfa"
+)
+
+// RegexRule names the tokens matched by Pattern, in regexp/syntax Perl syntax.
+type RegexRule struct {
+	Typ     TokenType
+	Pattern string
+}
+
+// Regex is a shorthand for a RegexRule.
+func Regex(typ TokenType, pattern string) RegexRule {
+	return RegexRule{
+		Typ:     typ,
+		Pattern: pattern,
+	}
+}
+
+// RegexSet is an ordered list of RegexRules compiled into one automaton that
+// finds the longest match of all rules in a single pass over the input. When
+// several rules match the same longest input, the earliest rule wins.
+type RegexSet struct {
+	rules   []RegexRule
+	machine *dfa.DFA
+}
+
+// CompileRegexSet compiles rules into a RegexSet. Patterns are anchored at the
+// current position; explicit anchors and patterns matching the empty string are
+// rejected.
+func CompileRegexSet(rules ...RegexRule) (*RegexSet, error) {
+	patterns := make([]dfa.Rule, len(rules))
+	for i, rule := range rules {
+		patterns[i] = dfa.Rule{Pattern: rule.Pattern}
+	}
+	machine, err := dfa.Compile(patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("lexer: %w", err)
+	}
+	return &RegexSet{
+		rules:   rules,
+		machine: machine,
+	}, nil
+}
+
+// MustCompileRegexSet is like CompileRegexSet but panics on invalid rules.
+func MustCompileRegexSet(rules ...RegexRule) *RegexSet {
+	set, err := CompileRegexSet(rules...)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// Pattern returns the combined alternation of the rules, with rule i in a
+// group named r<i>, e.g. (?P<r0>if|else)|(?P<r1>[a-z]+). Groups of the rules
+// shift the group indexes, so look the rules up by name with SubexpNames.
+// Package regexp picks the first alternative that matches rather than the
+// longest match, so it may match a shorter prefix than Match and Consumer.
+func (s *RegexSet) Pattern() string {
+	alternatives := make([]string, len(s.rules))
+	for i, rule := range s.rules {
+		alternatives[i] = fmt.Sprintf("(?P<r%d>%s)", i, rule.Pattern)
+	}
+	return strings.Join(alternatives, "|")
+}
+
+// Match returns the TokenType and length in runes of the longest prefix of
+// input matched by any rule. ok is false if no rule matches.
+func (s *RegexSet) Match(input string) (typ TokenType, length int, ok bool) {
+	rule, length := s.longest(strings.NewReader(input).ReadRune)
+	if rule < 0 {
+		return "", 0, false
+	}
+	return s.rules[rule].Typ, length, true
+}
+
+// longest feeds the runes returned by read to the automaton until it rejects
+// them or read fails, and returns the rule and length in runes of the longest
+// match, or -1 if there is none.
+func (s *RegexSet) longest(read func() (rune, int, error)) (rule int, length int) {
+	rule, state := -1, 0
+	for n := 1; ; n++ {
+		r, _, err := read()
+		if err != nil {
+			return rule, length
+		}
+		if state = s.machine.Step(state, r); state < 0 {
+			return rule, length
+		}
+		if accept := s.machine.States[state].Accept; accept >= 0 {
+			rule, length = accept, n
+		}
+	}
+}
+
+// Consumer returns a TokenConsumer emitting the longest match of any rule.
+func (s *RegexSet) Consumer() TokenConsumer {
+	return transactional(func(input BufferedRuneReader) (Token, bool) {
+		mark := input.Mark()
+		rule, length := s.longest(func() (rune, int, error) {
+			if input.EOF() {
+				return 0, 0, io.EOF
+			}
+			return input.Read(), 0, nil
+		})
+		mark.Rollback()
+		if rule < 0 {
+			return Token{}, false
+		}
+		value := newValueBuilder(input)
+		for i := 0; i < length; i++ {
+			value.WriteRune(input.Read())
+		}
+		return t(s.rules[rule].Typ, value.String()), true
+	})
+}
+
+// Lex scans input using the set as the only TokenConsumer.
+func (s *RegexSet) Lex(input BufferedRuneReader, visitor Visitor, eofToken, errorToken TokenType) {
+	LexStatic(input, visitor, eofToken, errorToken, s.Consumer())
+}
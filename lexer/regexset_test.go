@@ -0,0 +1,89 @@
+package lexer
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	TokenTypeKeyword  TokenType = "KEYWORD"
+	TokenTypeOperator TokenType = "OP"
+)
+
+var SexpRegexSet = MustCompileRegexSet(
+	Regex(TokenTypeStart, `\(`),
+	Regex(TokenTypeEnd, `\)`),
+	Regex(TokenTypeSymbol, `[a-zA-Z0-9\-_$#%&]+`),
+	Regex(TokenTypeWhitespace, `\s+`),
+	Regex(TokenTypeString, `"[^"]*"|'[^']*'`),
+)
+
+var _ = Describe("RegexSet", func() {
+	It("rejects invalid patterns", func() {
+		_, err := CompileRegexSet(Regex(TokenTypeSymbol, `[a-`))
+		Expect(err).To(HaveOccurred())
+		_, err = CompileRegexSet(Regex(TokenTypeSymbol, `a*`))
+		Expect(err).To(HaveOccurred())
+		Expect(func() { MustCompileRegexSet(Regex(TokenTypeSymbol, `^a`)) }).To(Panic())
+	})
+	It("joins its patterns into one alternation", func() {
+		set := MustCompileRegexSet(Regex(TokenTypeKeyword, `if|else`), Regex(TokenTypeSymbol, `[a-z]+`))
+		Expect(set.Pattern()).To(Equal(`(?P<r0>if|else)|(?P<r1>[a-z]+)`))
+	})
+	It("names the group of every rule", func() {
+		set := MustCompileRegexSet(Regex("not-an-identifier", `(a)b`), Regex("not-an-identifier", `c`))
+		pattern, err := regexp.Compile(set.Pattern())
+		Expect(err).NotTo(HaveOccurred())
+		match := pattern.FindStringSubmatchIndex("c")
+		var matched []string
+		for i, name := range pattern.SubexpNames() {
+			if name != "" && match[2*i] >= 0 {
+				matched = append(matched, name)
+			}
+		}
+		Expect(matched).To(Equal([]string{"r1"}))
+	})
+	It("prefers the longest match", func() {
+		set := MustCompileRegexSet(Regex(TokenTypeKeyword, `if|else`), Regex(TokenTypeSymbol, `[a-z]+`))
+		typ, length, ok := set.Match("iffy")
+		Expect(ok).To(BeTrue())
+		Expect(typ).To(Equal(TokenTypeSymbol))
+		Expect(length).To(Equal(4))
+	})
+	It("prefers the earlier rule among matches of equal length", func() {
+		set := MustCompileRegexSet(Regex(TokenTypeKeyword, `if|else`), Regex(TokenTypeSymbol, `[a-z]+`))
+		typ, length, _ := set.Match("if x")
+		Expect(typ).To(Equal(TokenTypeKeyword))
+		Expect(length).To(Equal(2))
+	})
+	It("reports no match", func() {
+		_, _, ok := SexpRegexSet.Match("*")
+		Expect(ok).To(BeFalse())
+	})
+	It("leaves the reader untouched when no rule matches", func() {
+		reader := StringReader(`"open`)
+		_, valid := SexpRegexSet.Consumer()(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+	})
+	It("consumes only the longest match", func() {
+		set := MustCompileRegexSet(Regex(TokenTypeOperator, `<|<=|<<=`))
+		reader := StringReader("<<x")
+		tok, valid := set.Consumer()(reader)
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("<"))
+		Expect(reader.Offset()).To(Equal(1))
+	})
+	It("lexes like the individual consumers", func() {
+		input := `(def x "a b" (add 'c' $1 true 42))`
+		expected := RecordingVisitor{}
+		LexStatic(StringReader(input), expected.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		for _, reader := range []BufferedRuneReader{StringReader(input), SourceStringReader(input)} {
+			rv := RecordingVisitor{}
+			SexpRegexSet.Lex(reader, rv.visit, TokenTypeEOF, TokenTypeError)
+			Expect(rv.tokens).To(Equal(expected.tokens))
+		}
+	})
+})
@@ -0,0 +1,101 @@
+package shell
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+var ansiEscapes = map[rune]rune{
+	'a':  '\a',
+	'b':  '\b',
+	'e':  '\x1b',
+	'E':  '\x1b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+	'\'': '\'',
+	'"':  '"',
+	'?':  '?',
+}
+
+// readANSIQuoted reads the rest of a $'...' string, decoding the backslash
+// escapes of ANSI C: the single letter escapes, \nnn octal up to \377, \xHH
+// hex and \uHHHH and \UHHHHHHHH code points.
+func readANSIQuoted(input lexer.BufferedRuneReader, read func() rune, value *strings.Builder) error {
+	for {
+		if input.EOF() {
+			return errUnterminated
+		}
+		c := read()
+		if c == '\'' {
+			return nil
+		}
+		if c != '\\' {
+			value.WriteRune(c)
+			continue
+		}
+		if input.EOF() {
+			return errUnterminated
+		}
+		escape := read()
+		if decoded, ok := ansiEscapes[escape]; ok {
+			value.WriteRune(decoded)
+			continue
+		}
+		switch escape {
+		case 'x':
+			readCode(input, read, value, escape, 2)
+		case 'u':
+			readCode(input, read, value, escape, 4)
+		case 'U':
+			readCode(input, read, value, escape, 8)
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// Octal escapes are limited to \377, the largest byte.
+			more := 2
+			if escape > '3' {
+				more = 1
+			}
+			digits := string(escape) + readDigits(input, read, 8, more)
+			n, _ := strconv.ParseUint(digits, 8, 32)
+			value.WriteByte(byte(n))
+		default:
+			value.WriteRune(c)
+			value.WriteRune(escape)
+		}
+	}
+}
+
+// readCode decodes up to max hex digits as a byte for \x or a code point for
+// \u and \U. Without digits the escape is kept literally.
+func readCode(input lexer.BufferedRuneReader, read func() rune, value *strings.Builder, escape rune, max int) {
+	digits := readDigits(input, read, 16, max)
+	if digits == "" {
+		value.WriteRune('\\')
+		value.WriteRune(escape)
+		return
+	}
+	n, _ := strconv.ParseUint(digits, 16, 32)
+	if escape == 'x' {
+		value.WriteByte(byte(n))
+	} else {
+		value.WriteRune(rune(n))
+	}
+}
+
+func readDigits(input lexer.BufferedRuneReader, read func() rune, base, max int) string {
+	var digits strings.Builder
+	for digits.Len() < max && !input.EOF() && isDigit(input.Peek(), base) {
+		digits.WriteRune(read())
+	}
+	return digits.String()
+}
+
+func isDigit(r rune, base int) bool {
+	_, err := strconv.ParseUint(string(r), base, 8)
+	return err == nil
+}
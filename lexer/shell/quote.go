@@ -0,0 +1,35 @@
+package shell
+
+import (
+	"strings"
+)
+
+// Quote returns s in a form a POSIX shell reads as a single word with value s.
+// Words consisting only of safe characters are returned unchanged, all others
+// are enclosed in single quotes.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, isUnsafe) == -1 {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// Join quotes each of words and joins them with spaces.
+func Join(words ...string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = Quote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func isUnsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("@%+=:,./_-", r)
+}
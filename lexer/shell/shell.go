@@ -0,0 +1,171 @@
+// Package shell splits command lines into words following the POSIX shell
+// quoting rules, extended by the $'...' quoting of bash, and quotes words so a
+// shell reads them back unchanged. Operators, expansions and substitutions are
+// not interpreted: $ and ` are ordinary characters outside of $'...'.
+package shell
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+var errUnterminated = errors.New("unterminated quote")
+
+// Word is a shell word with its raw span in the input and its unquoted value.
+type Word struct {
+	Raw    string
+	Value  string
+	Offset int
+}
+
+// ConsumeWord consumes a shell word. Value holds the raw lexeme, Data the
+// unquoted value as string. Words with unterminated quotes do not match.
+func ConsumeWord(typ lexer.TokenType) lexer.TokenConsumer {
+	return func(input lexer.BufferedRuneReader) (lexer.Token, bool) {
+		mark := input.Mark()
+		raw, value, err := readWord(input)
+		if err != nil || raw == "" {
+			mark.Rollback()
+			return lexer.Token{}, false
+		}
+		mark.Commit()
+		return lexer.Token{Typ: typ, Value: raw, Data: value}, true
+	}
+}
+
+// ConsumeComment consumes a comment starting with # up to, but not including,
+// the end of the line.
+func ConsumeComment(typ lexer.TokenType) lexer.TokenConsumer {
+	return func(input lexer.BufferedRuneReader) (lexer.Token, bool) {
+		if input.EOF() || input.Peek() != '#' {
+			return lexer.Token{}, false
+		}
+		var raw strings.Builder
+		for !input.EOF() && input.Peek() != '\n' {
+			raw.WriteRune(input.Read())
+		}
+		return lexer.Token{Typ: typ, Value: raw.String()}, true
+	}
+}
+
+// ConsumeBlank consumes spaces, tabs and newlines separating words.
+func ConsumeBlank(typ lexer.TokenType) lexer.TokenConsumer {
+	return lexer.ConsumeRunes(typ, " \t\n")
+}
+
+// Split splits s into words, skipping blanks and comments. An unterminated
+// quote is reported as *lexer.UnterminatedError.
+func Split(s string) ([]Word, error) {
+	input := lexer.StringReader(s)
+	skipComment := ConsumeComment("")
+	var words []Word
+	for {
+		for !input.EOF() && isBlank(input.Peek()) {
+			input.Read()
+		}
+		if input.EOF() {
+			return words, nil
+		}
+		if _, ok := skipComment(input); ok {
+			continue
+		}
+		offset := input.Offset()
+		raw, value, err := readWord(input)
+		if err != nil {
+			return words, err
+		}
+		words = append(words, Word{Raw: raw, Value: value, Offset: offset})
+	}
+}
+
+// Fields returns the unquoted values of the words in s.
+func Fields(s string) ([]string, error) {
+	words, err := Split(s)
+	values := make([]string, len(words))
+	for i, word := range words {
+		values[i] = word.Value
+	}
+	return values, err
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// readWord reads a word up to the next unquoted blank, returning its raw and
+// unquoted form.
+func readWord(input lexer.BufferedRuneReader) (string, string, error) {
+	var raw, value strings.Builder
+	start := input.Offset()
+	read := func() rune {
+		r := input.Read()
+		raw.WriteRune(r)
+		return r
+	}
+	unterminated := func(terminator string) error {
+		return &lexer.UnterminatedError{Offset: start, Terminator: terminator, Depth: 1}
+	}
+	for !input.EOF() && !isBlank(input.Peek()) {
+		switch r := read(); {
+		case r == '\\':
+			if input.EOF() {
+				value.WriteRune(r)
+			} else if next := read(); next != '\n' {
+				value.WriteRune(next)
+			}
+		case r == '\'':
+			for {
+				if input.EOF() {
+					return "", "", unterminated("'")
+				}
+				if c := read(); c == '\'' {
+					break
+				} else {
+					value.WriteRune(c)
+				}
+			}
+		case r == '"':
+			if err := readDoubleQuoted(input, read, &value); err != nil {
+				return "", "", unterminated(`"`)
+			}
+		case r == '$' && !input.EOF() && input.Peek() == '\'':
+			read()
+			if err := readANSIQuoted(input, read, &value); err != nil {
+				return "", "", unterminated("'")
+			}
+		default:
+			value.WriteRune(r)
+		}
+	}
+	return raw.String(), value.String(), nil
+}
+
+// readDoubleQuoted reads the rest of a double quoted string, in which a
+// backslash only escapes $, `, ", \ and newline.
+func readDoubleQuoted(input lexer.BufferedRuneReader, read func() rune, value *strings.Builder) error {
+	for {
+		if input.EOF() {
+			return errUnterminated
+		}
+		switch c := read(); c {
+		case '"':
+			return nil
+		case '\\':
+			if input.EOF() {
+				return errUnterminated
+			}
+			switch next := read(); next {
+			case '\n':
+			case '$', '`', '"', '\\':
+				value.WriteRune(next)
+			default:
+				value.WriteRune(c)
+				value.WriteRune(next)
+			}
+		default:
+			value.WriteRune(c)
+		}
+	}
+}
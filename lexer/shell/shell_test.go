@@ -0,0 +1,103 @@
+package shell
+
+import (
+	"github.com/mtrense/parsertk/lexer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func fields(s string) []string {
+	values, err := Fields(s)
+	Expect(err).ToNot(HaveOccurred())
+	return values
+}
+
+var _ = Describe("Split", func() {
+	It("splits at blanks", func() {
+		Expect(fields(" ls\t-l  \n/tmp ")).To(Equal([]string{"ls", "-l", "/tmp"}))
+	})
+	It("reports raw spans and offsets", func() {
+		words, err := Split(`echo "a b"'c' d\ e`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(words).To(Equal([]Word{
+			{Raw: "echo", Value: "echo", Offset: 0},
+			{Raw: `"a b"'c'`, Value: "a bc", Offset: 5},
+			{Raw: `d\ e`, Value: "d e", Offset: 14},
+		}))
+	})
+	It("keeps single quoted text literally", func() {
+		Expect(fields(`'a\b "c" $x'`)).To(Equal([]string{`a\b "c" $x`}))
+	})
+	It("handles backslashes in double quotes", func() {
+		Expect(fields(`"\$ \` + "`" + ` \" \\ \a"`)).To(Equal([]string{"$ ` \" \\ \\a"}))
+	})
+	It("removes line continuations", func() {
+		Expect(fields("ab\\\ncd \"e\\\nf\"")).To(Equal([]string{"abcd", "ef"}))
+	})
+	It("keeps empty quoted words", func() {
+		Expect(fields(`a '' "" b`)).To(Equal([]string{"a", "", "", "b"}))
+	})
+	It("decodes ANSI C quoting", func() {
+		Expect(fields(`$'a\tb\n\'\x41\101é\U0001F600\q' x$'\x'`)).To(Equal([]string{"a\tb\n'AAé😀\\q", "x\\x"}))
+	})
+	It("limits octal escapes to a byte", func() {
+		Expect(fields(`$'\377\477\1234'`)).To(Equal([]string{"\xff'7S4"}))
+	})
+	It("treats $ outside of $'...' as ordinary character", func() {
+		Expect(fields(`$HOME "$x"`)).To(Equal([]string{"$HOME", "$x"}))
+	})
+	It("skips comments", func() {
+		Expect(fields("a # b c\nd e#f")).To(Equal([]string{"a", "d", "e#f"}))
+	})
+	It("reports unterminated quotes", func() {
+		words, err := Split(`a "b c`)
+		Expect(words).To(HaveLen(1))
+		Expect(err).To(Equal(&lexer.UnterminatedError{Offset: 2, Terminator: `"`, Depth: 1}))
+		_, err = Split(`$'b`)
+		Expect(err).To(HaveOccurred())
+		_, err = Split(`'b`)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ConsumeWord", func() {
+	It("emits raw lexeme and unquoted value", func() {
+		var tokens []lexer.Token
+		lexer.LexStatic(lexer.StringReader(`cp "my file" b # copy`), func(tok lexer.Token) {
+			tokens = append(tokens, tok)
+		}, "EOF", "ERR", ConsumeBlank("WS"), ConsumeComment("COMMENT"), ConsumeWord("WORD"))
+		Expect(tokens).To(Equal([]lexer.Token{
+			{Typ: "WORD", Value: "cp", Data: "cp", Offset: 0},
+			{Typ: "WS", Value: " ", Offset: 2},
+			{Typ: "WORD", Value: `"my file"`, Data: "my file", Offset: 3},
+			{Typ: "WS", Value: " ", Offset: 12},
+			{Typ: "WORD", Value: "b", Data: "b", Offset: 13},
+			{Typ: "WS", Value: " ", Offset: 14},
+			{Typ: "COMMENT", Value: "# copy", Offset: 15},
+			{Typ: "EOF", Offset: 21},
+		}))
+	})
+	It("does not match unterminated words", func() {
+		reader := lexer.StringReader(`ab'c`)
+		_, valid := ConsumeWord("WORD")(reader)
+		Expect(valid).To(BeFalse())
+		Expect(reader.Offset()).To(BeZero())
+	})
+})
+
+var _ = Describe("Quote", func() {
+	It("leaves safe words unchanged", func() {
+		Expect(Quote("/usr/bin/env")).To(Equal("/usr/bin/env"))
+	})
+	It("quotes empty words", func() {
+		Expect(Quote("")).To(Equal("''"))
+	})
+	It("quotes unsafe words", func() {
+		Expect(Quote("a b")).To(Equal("'a b'"))
+		Expect(Quote("it's")).To(Equal(`'it'\''s'`))
+	})
+	It("round trips through Split", func() {
+		words := []string{"", "a b", "it's", `"\$x`, "tab\there", "new\nline", "ü", "#x", "$'y'"}
+		Expect(fields(Join(words...))).To(Equal(words))
+	})
+})
@@ -0,0 +1,107 @@
+package lexer
+
+import (
+	"unicode/utf8"
+)
+
+// SourceReader is a BufferedRuneReader that retains its complete source, so
+// consumers can slice token values out of it instead of copying them rune by rune.
+type SourceReader interface {
+	BufferedRuneReader
+	// Since returns the source text between the given rune offset and the current
+	// position without copying it.
+	Since(offset int) string
+}
+
+type sourceReader struct {
+	source string
+	ascii  bool
+	offset int
+	pos    int
+	marks  *MarkStack
+}
+
+func (s *sourceReader) Mark() Mark {
+	return s.marks.Push(s.offset, s.pos)
+}
+
+func (s *sourceReader) Offset() int {
+	return s.offset
+}
+
+func (s *sourceReader) Read() rune {
+	if s.EOF() {
+		return '\uFFFD'
+	}
+	if b := s.source[s.pos]; b < utf8.RuneSelf {
+		s.pos++
+		s.offset++
+		return rune(b)
+	}
+	r, size := utf8.DecodeRuneInString(s.source[s.pos:])
+	s.pos += size
+	s.offset++
+	return r
+}
+
+func (s *sourceReader) Peek() rune {
+	if s.EOF() {
+		return '\uFFFD'
+	}
+	if b := s.source[s.pos]; b < utf8.RuneSelf {
+		return rune(b)
+	}
+	r, _ := utf8.DecodeRuneInString(s.source[s.pos:])
+	return r
+}
+
+func (s *sourceReader) Rewind() {
+	s.marks.Rewind()
+}
+
+func (s *sourceReader) Error() error {
+	return nil
+}
+
+func (s *sourceReader) EOF() bool {
+	return s.pos >= len(s.source)
+}
+
+func (s *sourceReader) Since(offset int) string {
+	if offset >= s.offset {
+		return ""
+	}
+	if s.ascii {
+		return s.source[offset:s.pos]
+	}
+	start := s.pos
+	for i := offset; i < s.offset && start > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(s.source[:start])
+		start -= size
+	}
+	return s.source[start:s.pos]
+}
+
+// SourceStringReader returns a SourceReader over input. Unlike StringReader it
+// does not convert the input into runes up front, and pure ASCII input is read
+// byte by byte without UTF-8 decoding.
+func SourceStringReader(input string) SourceReader {
+	reader := &sourceReader{
+		source: input,
+		ascii:  isASCII(input),
+	}
+	reader.marks = NewMarkStack(func(offset, pos int) {
+		reader.offset = offset
+		reader.pos = pos
+	})
+	return reader
+}
+
+func isASCII(input string) bool {
+	for i := 0; i < len(input); i++ {
+		if input[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
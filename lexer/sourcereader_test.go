@@ -0,0 +1,85 @@
+package lexer
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sourceReader", func() {
+	It("reports EOF for an empty string", func() {
+		Expect(SourceStringReader("").EOF()).To(BeTrue())
+	})
+	It("returns unicode replacement char for an empty string on peek and read", func() {
+		s := SourceStringReader("")
+		Expect(s.Peek()).To(Equal('�'))
+		Expect(s.Read()).To(Equal('�'))
+	})
+	It("reads ASCII input byte by byte", func() {
+		s := SourceStringReader("abc")
+		Expect(s.(*sourceReader).ascii).To(BeTrue())
+		Expect(s.Read()).To(Equal('a'))
+		Expect(s.Peek()).To(Equal('b'))
+		Expect(s.Offset()).To(Equal(1))
+	})
+	It("counts offsets in runes for multi-byte input", func() {
+		s := SourceStringReader("äöü")
+		Expect(s.(*sourceReader).ascii).To(BeFalse())
+		Expect(s.Read()).To(Equal('ä'))
+		Expect(s.Read()).To(Equal('ö'))
+		Expect(s.Offset()).To(Equal(2))
+		Expect(s.(*sourceReader).pos).To(Equal(4))
+	})
+	It("rewinds rune offset and byte position together", func() {
+		s := SourceStringReader("aäbö")
+		s.Read()
+		s.Mark()
+		s.Read()
+		s.Read()
+		s.Rewind()
+		Expect(s.Offset()).To(Equal(1))
+		Expect(s.Read()).To(Equal('ä'))
+	})
+	It("slices the text read since an offset", func() {
+		s := SourceStringReader("xäöy")
+		s.Read()
+		start := s.Offset()
+		s.Read()
+		s.Read()
+		Expect(s.Since(start)).To(Equal("äö"))
+		Expect(s.Since(s.Offset())).To(Equal(""))
+	})
+})
+
+var _ = Describe("Consumers on a SourceReader", func() {
+	It("return values sliced from the source", func() {
+		consumer := ConsumeRunes(TokenTypeSymbol, "abc")
+		reader := SourceStringReader("abcabcdef")
+		var (
+			tok   Token
+			valid bool
+		)
+		allocs := testing.AllocsPerRun(10, func() {
+			reader.Mark()
+			tok, valid = consumer(reader)
+			reader.Rewind()
+		})
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("abcabc"))
+		Expect(allocs).To(BeZero())
+	})
+	It("match the token values produced by StringReader", func() {
+		input := "(déf (f x) \"a \\\"b\\\"\" ( g  'ç' ))"
+		rv, srv := RecordingVisitor{}, RecordingVisitor{}
+		LexStatic(StringReader(input), rv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		LexStatic(SourceStringReader(input), srv.visit, TokenTypeEOF, TokenTypeError, SexpTokens...)
+		Expect(srv.tokens).To(Equal(rv.tokens))
+	})
+	It("slice regex matches from the source", func() {
+		tok, valid := ConsumeRegex(TokenTypeSymbol, regexp.MustCompile("^äb+c$"))(SourceStringReader("äbbbcdef"))
+		Expect(valid).To(BeTrue())
+		Expect(tok.Value).To(Equal("äbbbc"))
+	})
+})
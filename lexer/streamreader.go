@@ -1,11 +1,16 @@
 package lexer
 
 type BufferedRuneReader interface {
-	Mark() int
+	// Mark remembers the current position until the returned Mark is committed or
+	// rolled back.
+	Mark() Mark
 	Offset() int
 	Read() rune
 	Peek() rune
+	// Rewind rolls back the innermost mark.
 	Rewind()
+	// Error reports problems reading the input. Readers fed incrementally return
+	// ErrIncomplete while a consumer ran into the end of the data available so far.
 	Error() error
 	EOF() bool
 }
@@ -13,12 +18,11 @@ type BufferedRuneReader interface {
 type stringReader struct {
 	input  []rune
 	offset int
-	marks  []int
+	marks  *MarkStack
 }
 
-func (s *stringReader) Mark() int {
-	s.marks = append(s.marks, s.offset)
-	return s.offset
+func (s *stringReader) Mark() Mark {
+	return s.marks.Push(s.offset, s.offset)
 }
 
 func (s *stringReader) Offset() int {
@@ -42,11 +46,7 @@ func (s *stringReader) Peek() rune {
 }
 
 func (s *stringReader) Rewind() {
-	if len(s.marks) > 0 {
-		lastMark := s.marks[len(s.marks)-1]
-		s.marks = s.marks[0 : len(s.marks)-1]
-		s.offset = lastMark
-	}
+	s.marks.Rewind()
 }
 
 func (s *stringReader) Error() error {
@@ -58,9 +58,25 @@ func (s *stringReader) EOF() bool {
 }
 
 func StringReader(input string) BufferedRuneReader {
-	return &stringReader{
-		input:  []rune(input),
-		offset: 0,
-		marks:  make([]int, 0),
+	return StringReaderFrom(input, 0)
+}
+
+// StringReaderFrom returns a reader over input that is positioned at the given
+// rune offset, e.g. to resume lexing from a State. Offsets outside of input are
+// clamped to its start or end.
+func StringReaderFrom(input string, offset int) BufferedRuneReader {
+	runes := []rune(input)
+	if offset < 0 {
+		offset = 0
+	} else if offset > len(runes) {
+		offset = len(runes)
+	}
+	reader := &stringReader{
+		input:  runes,
+		offset: offset,
 	}
+	reader.marks = NewMarkStack(func(offset, _ int) {
+		reader.offset = offset
+	})
+	return reader
 }
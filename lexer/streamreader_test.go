@@ -59,3 +59,20 @@ var _ = Describe("stringReader", func() {
 		Expect(s.Read()).To(Equal('\uFFFD'))
 	})
 })
+
+var _ = Describe("StringReaderFrom", func() {
+	It("starts reading at the given offset", func() {
+		s := StringReaderFrom("aäbc", 2)
+		Expect(s.Offset()).To(Equal(2))
+		Expect(s.Read()).To(Equal('b'))
+	})
+	It("clamps offsets outside of the input", func() {
+		s := StringReaderFrom("ab", -1)
+		Expect(s.Offset()).To(Equal(0))
+		Expect(s.Read()).To(Equal('a'))
+		s = StringReaderFrom("ab", 5)
+		Expect(s.Offset()).To(Equal(2))
+		Expect(s.EOF()).To(BeTrue())
+		Expect(s.Peek()).To(Equal('\uFFFD'))
+	})
+})
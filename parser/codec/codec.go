@@ -0,0 +1,145 @@
+// Package codec serializes INode trees to JSON, S-expressions and XML and
+// rebuilds them through a Registry of node factories. Every node is stored with
+// its NodeType(), String() value, Bracket() span and children.
+package codec
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mtrense/parsertk/parser"
+)
+
+// Record is the serialized form of a node.
+type Record struct {
+	Type     string    `json:"type" xml:"type,attr"`
+	Value    string    `json:"value,omitempty" xml:"value,attr,omitempty"`
+	Start    int       `json:"start" xml:"start,attr"`
+	Length   int       `json:"length" xml:"length,attr"`
+	Children []*Record `json:"children,omitempty" xml:"node"`
+}
+
+// FromTree converts the tree below node into Records.
+func FromTree(node parser.INode) *Record {
+	bracket := node.Bracket()
+	record := &Record{
+		Type:   node.NodeType(),
+		Value:  node.String(),
+		Start:  bracket.StartOffset,
+		Length: bracket.Length,
+	}
+	for _, child := range node.Children() {
+		record.Children = append(record.Children, FromTree(child))
+	}
+	return record
+}
+
+// Format writes and reads Records in one serialization format.
+type Format interface {
+	Encode(w io.Writer, record *Record) error
+	Decode(r io.Reader) (*Record, error)
+}
+
+// Encode writes the tree below node to w.
+func Encode(w io.Writer, node parser.INode, format Format) error {
+	return format.Encode(w, FromTree(node))
+}
+
+// Decode reads a tree from r, creating its nodes with registry.
+func Decode(r io.Reader, format Format, registry *Registry) (parser.INode, error) {
+	record, err := format.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Build(record)
+}
+
+// Factory creates a node, without children, from its Record.
+type Factory func(record *Record) parser.INode
+
+// Registry maps node types to the factories creating them.
+type Registry struct {
+	factories map[string]Factory
+	fallback  Factory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+// Register sets the factory for nodes of nodeType.
+func (s *Registry) Register(nodeType string, factory Factory) *Registry {
+	s.factories[nodeType] = factory
+	return s
+}
+
+// Otherwise sets the factory for node types without registered factory.
+// Without it, such node types are an error.
+func (s *Registry) Otherwise(factory Factory) *Registry {
+	s.fallback = factory
+	return s
+}
+
+// Create creates a node of nodeType without children, or returns nil if there
+// is no factory for nodeType. It adapts a Registry to converters taking a node
+// factory, like cst.ToTree.
+func (s *Registry) Create(nodeType, value string, bracket parser.Bracket) parser.INode {
+	factory := s.factory(nodeType)
+	if factory == nil {
+		return nil
+	}
+	return factory(&Record{Type: nodeType, Value: value, Start: bracket.StartOffset, Length: bracket.Length})
+}
+
+func (s *Registry) factory(nodeType string) Factory {
+	if factory, ok := s.factories[nodeType]; ok {
+		return factory
+	}
+	return s.fallback
+}
+
+// Build creates the tree described by record.
+func (s *Registry) Build(record *Record) (parser.INode, error) {
+	factory := s.factory(record.Type)
+	if factory == nil {
+		return nil, fmt.Errorf("codec: no factory for node type %q", record.Type)
+	}
+	node := factory(record)
+	for _, childRecord := range record.Children {
+		child, err := s.Build(childRecord)
+		if err != nil {
+			return nil, err
+		}
+		if err := parser.AppendChild(node, child); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// GenericNode is a node restored from a Record as is. It serves for node types
+// that need no specific Go type.
+type GenericNode struct {
+	parser.Node
+	Type  string
+	Value string
+}
+
+// Generic is a Factory creating GenericNodes.
+func Generic(record *Record) parser.INode {
+	return &GenericNode{
+		Node:  parser.NewNodeAt(nil, parser.Bracket{StartOffset: record.Start, Length: record.Length}),
+		Type:  record.Type,
+		Value: record.Value,
+	}
+}
+
+func (s *GenericNode) NodeType() string {
+	return s.Type
+}
+
+func (s *GenericNode) String() string {
+	return s.Value
+}
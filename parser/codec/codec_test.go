@@ -0,0 +1,131 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/mtrense/parsertk/parser"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type identNode struct {
+	parser.Node
+	name string
+}
+
+func (s *identNode) NodeType() string {
+	return "ident"
+}
+
+func (s *identNode) String() string {
+	return s.name
+}
+
+func generic(typ, value string, start, length int, children ...parser.INode) parser.INode {
+	node := Generic(&Record{Type: typ, Value: value, Start: start, Length: length})
+	Expect(parser.AppendChild(node, children...)).To(Succeed())
+	return node
+}
+
+func sample() parser.INode {
+	return generic("call", "", 0, 11,
+		generic("ident", "print", 0, 5),
+		generic("string literal", "a \"b\"\n<&>", 6, 4),
+	)
+}
+
+func roundTrip(node parser.INode, format Format, registry *Registry) parser.INode {
+	var buffer bytes.Buffer
+	Expect(Encode(&buffer, node, format)).To(Succeed())
+	decoded, err := Decode(&buffer, format, registry)
+	Expect(err).ToNot(HaveOccurred())
+	return decoded
+}
+
+var _ = Describe("FromTree", func() {
+	It("records type, value, span and children", func() {
+		Expect(FromTree(sample())).To(Equal(&Record{Type: "call", Start: 0, Length: 11, Children: []*Record{
+			{Type: "ident", Value: "print", Start: 0, Length: 5},
+			{Type: "string literal", Value: "a \"b\"\n<&>", Start: 6, Length: 4},
+		}}))
+	})
+})
+
+var _ = Describe("Formats", func() {
+	It("encodes JSON", func() {
+		var buffer bytes.Buffer
+		Expect(Encode(&buffer, generic("call", "", 0, 5, generic("ident", "f", 0, 1)), JSON)).To(Succeed())
+		Expect(buffer.String()).To(Equal(`{
+  "type": "call",
+  "start": 0,
+  "length": 5,
+  "children": [
+    {
+      "type": "ident",
+      "value": "f",
+      "start": 0,
+      "length": 1
+    }
+  ]
+}
+`))
+	})
+	It("encodes S-expressions", func() {
+		var buffer bytes.Buffer
+		Expect(Encode(&buffer, sample(), SExpr)).To(Succeed())
+		Expect(buffer.String()).To(Equal(`(call "" 0 11
+  (ident "print" 0 5)
+  ("string literal" "a \"b\"\n<&>" 6 4))
+`))
+	})
+	It("encodes XML", func() {
+		var buffer bytes.Buffer
+		Expect(Encode(&buffer, generic("call", "", 0, 5, generic("ident", "<f>", 0, 1)), XML)).To(Succeed())
+		Expect(buffer.String()).To(Equal(`<node type="call" start="0" length="5">
+  <node type="ident" value="&lt;f&gt;" start="0" length="1"></node>
+</node>
+`))
+	})
+	for name, format := range map[string]Format{"JSON": JSON, "S-expressions": SExpr, "XML": XML} {
+		format := format
+		It("round trips "+name, func() {
+			original := sample()
+			decoded := roundTrip(original, format, NewRegistry().Otherwise(Generic))
+			Expect(FromTree(decoded)).To(Equal(FromTree(original)))
+			Expect(decoded.Children()[0].Parent()).To(BeIdenticalTo(decoded))
+		})
+	}
+	It("reports malformed S-expressions", func() {
+		for _, source := range []string{"", "(call", `(call "" 0)`, `(call "" a 1)`, `(call x 0 1)`, `(call "" 0 1) x`, `(call "" 0 1 x)`, `(call "\q" 0 1)`, `("\q" "" 0 1)`, "#"} {
+			_, err := SExpr.Decode(strings.NewReader(source))
+			Expect(err).To(HaveOccurred(), source)
+		}
+	})
+})
+
+var _ = Describe("Registry", func() {
+	It("creates nodes with registered factories", func() {
+		registry := NewRegistry().
+			Register("ident", func(record *Record) parser.INode {
+				return &identNode{Node: parser.NewNodeAt(nil, parser.Bracket{StartOffset: record.Start, Length: record.Length}), name: record.Value}
+			}).
+			Otherwise(Generic)
+		decoded := roundTrip(sample(), JSON, registry)
+		ident, ok := decoded.Children()[0].(*identNode)
+		Expect(ok).To(BeTrue())
+		Expect(ident.name).To(Equal("print"))
+		Expect(ident.Bracket()).To(Equal(parser.Bracket{StartOffset: 0, Length: 5}))
+		Expect(decoded.Children()[1]).To(BeAssignableToTypeOf(&GenericNode{}))
+	})
+	It("creates single nodes", func() {
+		registry := NewRegistry().Register("ident", Generic)
+		node := registry.Create("ident", "x", parser.Bracket{StartOffset: 2, Length: 1})
+		Expect(FromTree(node)).To(Equal(&Record{Type: "ident", Value: "x", Start: 2, Length: 1}))
+		Expect(registry.Create("call", "", parser.Bracket{})).To(BeNil())
+	})
+	It("rejects unknown node types", func() {
+		_, err := NewRegistry().Register("call", Generic).Build(FromTree(sample()))
+		Expect(err).To(MatchError(`codec: no factory for node type "ident"`))
+	})
+})
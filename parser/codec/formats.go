@@ -0,0 +1,61 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+)
+
+var (
+	// JSON encodes every node as object with type, value, start, length and
+	// children.
+	JSON Format = jsonFormat{Indent: "  "}
+	// XML encodes every node as node element with type, value, start and length
+	// attributes and its children as child elements.
+	XML Format = xmlFormat{Indent: "  "}
+)
+
+type jsonFormat struct {
+	Indent string
+}
+
+func (s jsonFormat) Encode(w io.Writer, record *Record) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", s.Indent)
+	return encoder.Encode(record)
+}
+
+func (s jsonFormat) Decode(r io.Reader) (*Record, error) {
+	var record Record
+	if err := json.NewDecoder(r).Decode(&record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+type xmlRecord struct {
+	XMLName xml.Name `xml:"node"`
+	*Record
+}
+
+type xmlFormat struct {
+	Indent string
+}
+
+func (s xmlFormat) Encode(w io.Writer, record *Record) error {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", s.Indent)
+	if err := encoder.Encode(xmlRecord{Record: record}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (s xmlFormat) Decode(r io.Reader) (*Record, error) {
+	record := xmlRecord{Record: &Record{}}
+	if err := xml.NewDecoder(r).Decode(&record); err != nil {
+		return nil, err
+	}
+	return record.Record, nil
+}
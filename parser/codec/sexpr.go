@@ -0,0 +1,156 @@
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+// SExpr encodes every node as list (type "value" start length children...).
+// Types that are not plain symbols are written as strings.
+var SExpr Format = sexprFormat{}
+
+const (
+	sexprWhitespace lexer.TokenType = "WS"
+	sexprOpen       lexer.TokenType = "OPEN"
+	sexprClose      lexer.TokenType = "CLOSE"
+	sexprString     lexer.TokenType = "STRING"
+	sexprSymbol     lexer.TokenType = "SYMBOL"
+	sexprEOF        lexer.TokenType = "EOF"
+	sexprError      lexer.TokenType = "ERR"
+)
+
+var (
+	sexprTokens = lexer.MustCompileRegexSet(
+		lexer.Regex(sexprWhitespace, `\s+`),
+		lexer.Regex(sexprOpen, `\(`),
+		lexer.Regex(sexprClose, `\)`),
+		lexer.Regex(sexprString, `"([^"\\]|\\.)*"`),
+		lexer.Regex(sexprSymbol, `[^\s()"]+`),
+	)
+	plainSymbol = regexp.MustCompile(`^[^\s()"0-9][^\s()"]*$`)
+)
+
+type sexprFormat struct{}
+
+func (s sexprFormat) Encode(w io.Writer, record *Record) error {
+	out := bufio.NewWriter(w)
+	s.encode(out, record, 0)
+	out.WriteString("\n")
+	return out.Flush()
+}
+
+func (s sexprFormat) encode(out *bufio.Writer, record *Record, depth int) {
+	if depth > 0 {
+		out.WriteString("\n" + strings.Repeat("  ", depth))
+	}
+	typ := record.Type
+	if !plainSymbol.MatchString(typ) {
+		typ = strconv.Quote(typ)
+	}
+	fmt.Fprintf(out, "(%s %s %d %d", typ, strconv.Quote(record.Value), record.Start, record.Length)
+	for _, child := range record.Children {
+		s.encode(out, child, depth+1)
+	}
+	out.WriteString(")")
+}
+
+func (s sexprFormat) Decode(r io.Reader) (*Record, error) {
+	source, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []lexer.Token
+	sexprTokens.Lex(lexer.SourceStringReader(string(source)), func(tok lexer.Token) {
+		if tok.Typ != sexprWhitespace {
+			tokens = append(tokens, tok)
+		}
+	}, sexprEOF, sexprError)
+	d := &sexprDecoder{tokens: tokens}
+	record, err := d.record()
+	if err != nil {
+		return nil, err
+	}
+	if tok := d.next(); tok.Typ != sexprEOF {
+		return nil, d.unexpected(tok)
+	}
+	return record, nil
+}
+
+type sexprDecoder struct {
+	tokens []lexer.Token
+	pos    int
+}
+
+func (s *sexprDecoder) next() lexer.Token {
+	tok := s.tokens[s.pos]
+	if tok.Typ != sexprEOF && tok.Typ != sexprError {
+		s.pos++
+	}
+	return tok
+}
+
+func (s *sexprDecoder) unexpected(tok lexer.Token) error {
+	if tok.Typ == sexprEOF {
+		return fmt.Errorf("codec: unexpected end of S-expression")
+	}
+	return fmt.Errorf("codec: unexpected %q at offset %d", tok.Value, tok.Offset)
+}
+
+func (s *sexprDecoder) record() (*Record, error) {
+	if tok := s.next(); tok.Typ != sexprOpen {
+		return nil, s.unexpected(tok)
+	}
+	record := &Record{}
+	tok := s.next()
+	switch tok.Typ {
+	case sexprSymbol:
+		record.Type = tok.Value
+	case sexprString:
+		typ, err := strconv.Unquote(tok.Value)
+		if err != nil {
+			return nil, fmt.Errorf("codec: invalid string at offset %d: %w", tok.Offset, err)
+		}
+		record.Type = typ
+	default:
+		return nil, s.unexpected(tok)
+	}
+	tok = s.next()
+	if tok.Typ != sexprString {
+		return nil, s.unexpected(tok)
+	}
+	value, err := strconv.Unquote(tok.Value)
+	if err != nil {
+		return nil, fmt.Errorf("codec: invalid string at offset %d: %w", tok.Offset, err)
+	}
+	record.Value = value
+	for _, field := range []*int{&record.Start, &record.Length} {
+		tok = s.next()
+		n, err := strconv.Atoi(tok.Value)
+		if tok.Typ != sexprSymbol || err != nil {
+			return nil, s.unexpected(tok)
+		}
+		*field = n
+	}
+	for {
+		switch tok := s.tokens[s.pos]; tok.Typ {
+		case sexprClose:
+			s.next()
+			return record, nil
+		case sexprOpen:
+			child, err := s.record()
+			if err != nil {
+				return nil, err
+			}
+			record.Children = append(record.Children, child)
+		default:
+			return nil, s.unexpected(s.next())
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package cst
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCST(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CST Suite")
+}
@@ -0,0 +1,128 @@
+package cst
+
+import (
+	"github.com/mtrense/parsertk/parser"
+	"github.com/mtrense/parsertk/parser/codec"
+	"github.com/mtrense/parsertk/parser/internal/testutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var n = testutil.Spanned
+
+const source = " (f x) (f x)"
+
+// sample is the tree of source, with two equal calls.
+func sample() parser.INode {
+	return n("program", "", 1, 11,
+		n("call", "", 1, 5, n("ident", "f", 2, 1), n("ident", "x", 4, 1)),
+		n("call", "", 7, 5, n("ident", "f", 8, 1), n("ident", "x", 10, 1)))
+}
+
+var _ = Describe("Green", func() {
+	var cache *Cache
+
+	BeforeEach(func() {
+		cache = NewCache()
+	})
+
+	It("shares equal subtrees", func() {
+		first := cache.Node("call", cache.Token("ident", "f"), cache.Token("ident", "x"))
+		second := cache.Node("call", cache.Token("ident", "f"), cache.Token("ident", "x"))
+		Expect(second).To(BeIdenticalTo(first))
+		Expect(cache.Node("call", cache.Token("ident", "x"))).NotTo(BeIdenticalTo(first))
+		Expect(cache.Stats()).To(Equal(CacheStats{Lookups: 8, Hits: 4, Entries: 4}))
+	})
+	It("measures widths in runes", func() {
+		node := cache.Node("pair", cache.Token("string", `"ä"`), cache.Token("ident", "b"))
+		Expect(node.Width()).To(Equal(4))
+		Expect(node.Text()).To(Equal(`"ä"b`))
+	})
+})
+
+var _ = Describe("FromTree", func() {
+	var (
+		cache *Cache
+		root  *Red
+	)
+
+	BeforeEach(func() {
+		cache = NewCache()
+		green, err := FromTree(cache, sample(), source)
+		Expect(err).NotTo(HaveOccurred())
+		root = NewRoot(green)
+	})
+
+	It("is lossless", func() {
+		Expect(root.Text()).To(Equal(source))
+		Expect(root.Width()).To(Equal(12))
+	})
+	It("covers gaps with trivia", func() {
+		var kinds []string
+		for _, child := range root.Child(1).Children() {
+			kinds = append(kinds, child.Kind()+":"+child.Text())
+		}
+		Expect(root.Child(0).Kind()).To(Equal(Trivia))
+		Expect(kinds).To(Equal([]string{"#trivia:(", "ident:f", "#trivia: ", "ident:x", "#trivia:)"}))
+	})
+	It("interns equal subtrees", func() {
+		Expect(root.Child(1).Green()).To(BeIdenticalTo(root.Child(3).Green()))
+		Expect(root.Child(1).Offset()).To(Equal(1))
+		Expect(root.Child(3).Offset()).To(Equal(7))
+	})
+	It("rejects overlapping nodes", func() {
+		_, err := FromTree(cache, n("program", "", 0, 4, n("a", "", 0, 3), n("b", "", 2, 2)), "abcd")
+		Expect(err).To(HaveOccurred())
+	})
+	It("converts back into INodes", func() {
+		tree, err := ToTree(root, codec.NewRegistry().Otherwise(codec.Generic).Create)
+		Expect(err).NotTo(HaveOccurred())
+		expected := codec.FromTree(sample())
+		expected.Start, expected.Length = 0, 12
+		Expect(codec.FromTree(tree)).To(Equal(expected))
+	})
+	It("reports kinds the factory cannot create", func() {
+		_, err := ToTree(root, codec.NewRegistry().Register("program", codec.Generic).Create)
+		Expect(err).To(MatchError(`cst: no node for kind "call"`))
+	})
+})
+
+var _ = Describe("Red", func() {
+	var (
+		cache *Cache
+		root  *Red
+	)
+
+	BeforeEach(func() {
+		cache = NewCache()
+		green, err := FromTree(cache, sample(), source)
+		Expect(err).NotTo(HaveOccurred())
+		root = NewRoot(green)
+	})
+
+	It("finds tokens and their ancestors", func() {
+		token := root.TokenAt(10)
+		Expect(token.Text()).To(Equal("x"))
+		Expect(token.Offset()).To(Equal(10))
+		Expect(token.Parent().Offset()).To(Equal(7))
+		Expect(token.Parent().Parent()).To(Equal(root))
+		Expect(token.Root()).To(Equal(root))
+		Expect(root.TokenAt(12)).To(BeNil())
+	})
+	It("navigates siblings", func() {
+		token := root.TokenAt(4)
+		Expect(token.PrevSibling().Text()).To(Equal(" "))
+		Expect(token.PrevSibling().Offset()).To(Equal(3))
+		Expect(token.NextSibling().Offset()).To(Equal(5))
+		Expect(token.NextSibling().NextSibling()).To(BeNil())
+		Expect(root.PrevSibling()).To(BeNil())
+	})
+	It("replaces nodes sharing the rest of the tree", func() {
+		replaced := root.TokenAt(2).Replace(cache, cache.Token("ident", "print"))
+		newRoot := replaced.Root()
+		Expect(newRoot.Text()).To(Equal(" (print x) (f x)"))
+		Expect(newRoot.Child(3).Offset()).To(Equal(11))
+		Expect(newRoot.Child(3).Green()).To(BeIdenticalTo(root.Child(3).Green()))
+		Expect(root.Text()).To(Equal(source))
+	})
+})
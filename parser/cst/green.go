@@ -0,0 +1,125 @@
+// Package cst provides an immutable, lossless concrete syntax tree. Green nodes
+// store only their kind, width and children, or the text of tokens, so equal
+// subtrees are shared through a Cache and edits copy only the path to the
+// root. Red nodes are cursors over a green tree that compute absolute offsets
+// and parents on demand.
+package cst
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Green is an immutable node of a concrete syntax tree. Tokens have text and
+// no children, other nodes have children and no text of their own.
+type Green struct {
+	kind     string
+	text     string
+	width    int
+	token    bool
+	children []*Green
+}
+
+func (s *Green) Kind() string {
+	return s.kind
+}
+
+// Width is the number of runes covered by the node.
+func (s *Green) Width() int {
+	return s.width
+}
+
+func (s *Green) IsToken() bool {
+	return s.token
+}
+
+// Children returns the children of the node, which must not be modified.
+func (s *Green) Children() []*Green {
+	return s.children
+}
+
+// Text returns the source text covered by the node.
+func (s *Green) Text() string {
+	if s.token {
+		return s.text
+	}
+	var builder strings.Builder
+	s.writeText(&builder)
+	return builder.String()
+}
+
+func (s *Green) writeText(builder *strings.Builder) {
+	if s.token {
+		builder.WriteString(s.text)
+	}
+	for _, child := range s.children {
+		child.writeText(builder)
+	}
+}
+
+// CacheStats counts the green nodes requested from a Cache.
+type CacheStats struct {
+	Lookups int
+	Hits    int
+	Entries int
+}
+
+// Cache interns green nodes, so equal tokens and nodes with the same kind and
+// children are created only once.
+type Cache struct {
+	tokens map[tokenKey]*Green
+	nodes  map[string]*Green
+	stats  CacheStats
+}
+
+type tokenKey struct {
+	kind string
+	text string
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		tokens: make(map[tokenKey]*Green),
+		nodes:  make(map[string]*Green),
+	}
+}
+
+// Token returns the token of kind with text.
+func (s *Cache) Token(kind, text string) *Green {
+	s.stats.Lookups++
+	key := tokenKey{kind, text}
+	if green, ok := s.tokens[key]; ok {
+		s.stats.Hits++
+		return green
+	}
+	green := &Green{kind: kind, text: text, width: utf8.RuneCountInString(text), token: true}
+	s.tokens[key] = green
+	s.stats.Entries++
+	return green
+}
+
+// Node returns the node of kind with children.
+func (s *Cache) Node(kind string, children ...*Green) *Green {
+	s.stats.Lookups++
+	var key strings.Builder
+	key.WriteString(kind)
+	for _, child := range children {
+		fmt.Fprintf(&key, "\x00%p", child)
+	}
+	if green, ok := s.nodes[key.String()]; ok {
+		s.stats.Hits++
+		return green
+	}
+	green := &Green{kind: kind, children: append([]*Green(nil), children...)}
+	for _, child := range children {
+		green.width += child.width
+	}
+	s.nodes[key.String()] = green
+	s.stats.Entries++
+	return green
+}
+
+func (s *Cache) Stats() CacheStats {
+	return s.stats
+}
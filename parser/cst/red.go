@@ -0,0 +1,140 @@
+package cst
+
+import (
+	"sort"
+)
+
+// Red is a cursor on a green node, knowing its absolute offset and parent.
+// Reds are created on demand while navigating and are cheap to discard.
+type Red struct {
+	green  *Green
+	offset int
+	parent *Red
+	index  int
+}
+
+// NewRoot returns the cursor on the root green of a tree starting at offset 0.
+func NewRoot(green *Green) *Red {
+	return &Red{green: green}
+}
+
+func (s *Red) Green() *Green {
+	return s.green
+}
+
+func (s *Red) Kind() string {
+	return s.green.kind
+}
+
+func (s *Red) Text() string {
+	return s.green.Text()
+}
+
+func (s *Red) IsToken() bool {
+	return s.green.token
+}
+
+// Offset is the rune offset the node starts at.
+func (s *Red) Offset() int {
+	return s.offset
+}
+
+func (s *Red) Width() int {
+	return s.green.width
+}
+
+// End is the rune offset following the node.
+func (s *Red) End() int {
+	return s.offset + s.green.width
+}
+
+// Parent returns the cursor on the parent, or nil for the root.
+func (s *Red) Parent() *Red {
+	return s.parent
+}
+
+// Index is the position of the node among the children of its parent.
+func (s *Red) Index() int {
+	return s.index
+}
+
+func (s *Red) Root() *Red {
+	root := s
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
+
+func (s *Red) ChildCount() int {
+	return len(s.green.children)
+}
+
+// Child returns the cursor on the child at index.
+func (s *Red) Child(index int) *Red {
+	offset := s.offset
+	for _, child := range s.green.children[:index] {
+		offset += child.width
+	}
+	return &Red{green: s.green.children[index], offset: offset, parent: s, index: index}
+}
+
+func (s *Red) Children() []*Red {
+	result := make([]*Red, len(s.green.children))
+	offset := s.offset
+	for i, child := range s.green.children {
+		result[i] = &Red{green: child, offset: offset, parent: s, index: i}
+		offset += child.width
+	}
+	return result
+}
+
+// NextSibling returns the cursor on the following sibling, or nil.
+func (s *Red) NextSibling() *Red {
+	if s.parent == nil || s.index+1 >= len(s.parent.green.children) {
+		return nil
+	}
+	return &Red{green: s.parent.green.children[s.index+1], offset: s.End(), parent: s.parent, index: s.index + 1}
+}
+
+// PrevSibling returns the cursor on the preceding sibling, or nil.
+func (s *Red) PrevSibling() *Red {
+	if s.parent == nil || s.index == 0 {
+		return nil
+	}
+	green := s.parent.green.children[s.index-1]
+	return &Red{green: green, offset: s.offset - green.width, parent: s.parent, index: s.index - 1}
+}
+
+// TokenAt returns the cursor on the token covering offset, or nil if offset is
+// outside the node. Tokens without width are never returned.
+func (s *Red) TokenAt(offset int) *Red {
+	if offset < s.offset || offset >= s.End() {
+		return nil
+	}
+	node := s
+	for !node.IsToken() {
+		children := node.Children()
+		i := sort.Search(len(children), func(i int) bool {
+			return children[i].End() > offset
+		})
+		if i == len(children) {
+			return nil
+		}
+		node = children[i]
+	}
+	return node
+}
+
+// Replace returns the cursor on green in a new tree equal to this one but with
+// green in place of this node. Only the nodes on the path to the root are
+// created anew, everything else is shared with the old tree.
+func (s *Red) Replace(cache *Cache, green *Green) *Red {
+	if s.parent == nil {
+		return NewRoot(green)
+	}
+	siblings := append([]*Green(nil), s.parent.green.children...)
+	siblings[s.index] = green
+	parent := s.parent.Replace(cache, cache.Node(s.parent.green.kind, siblings...))
+	return &Red{green: green, offset: s.offset, parent: parent, index: s.index}
+}
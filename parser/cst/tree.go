@@ -0,0 +1,94 @@
+package cst
+
+import (
+	"fmt"
+
+	"github.com/mtrense/parsertk/parser"
+)
+
+// Trivia is the kind of the tokens covering source text not covered by any
+// leaf of a converted tree, like whitespace and comments.
+const Trivia = "#trivia"
+
+// FromTree converts the tree below root, parsed from source, into a green tree.
+// Leaves become tokens of their NodeType() with the source text of their
+// Bracket(), and gaps between nodes become Trivia tokens. The root covers all
+// of source. Values of inner nodes are not kept.
+func FromTree(cache *Cache, root parser.INode, source string) (*Green, error) {
+	text := []rune(source)
+	type frame struct {
+		cursor   int
+		end      int
+		children []*Green
+	}
+	frames := []*frame{{end: len(text)}}
+	var err error
+	parser.Walk(root, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		parent := frames[len(frames)-1]
+		bracket := node.Bracket()
+		start, end := bracket.StartOffset, bracket.StartOffset+bracket.Length
+		if len(path) == 1 {
+			start, end = 0, len(text)
+		}
+		if start < parent.cursor || end > parent.end || end < start {
+			err = fmt.Errorf("cst: %s node at %d..%d overlaps its siblings or exceeds its parent", node.NodeType(), start, end)
+			return parser.Stop
+		}
+		if start > parent.cursor {
+			parent.children = append(parent.children, cache.Token(Trivia, string(text[parent.cursor:start])))
+		}
+		parent.cursor = end
+		frames = append(frames, &frame{cursor: start, end: end})
+		return parser.Continue
+	}, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		f := frames[len(frames)-1]
+		frames = frames[:len(frames)-1]
+		var green *Green
+		if node.IsLeaf() && len(path) > 1 {
+			green = cache.Token(node.NodeType(), string(text[f.cursor:f.end]))
+		} else {
+			if f.end > f.cursor {
+				f.children = append(f.children, cache.Token(Trivia, string(text[f.cursor:f.end])))
+			}
+			green = cache.Node(node.NodeType(), f.children...)
+		}
+		parent := frames[len(frames)-1]
+		parent.children = append(parent.children, green)
+		return parser.Continue
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frames[0].children[0], nil
+}
+
+// NodeFactory creates a node of kind without children covering bracket. text
+// is the source text of tokens and empty for other nodes. It returns nil for
+// kinds it cannot create.
+type NodeFactory func(kind, text string, bracket parser.Bracket) parser.INode
+
+// ToTree converts the tree below red into INodes created by factory. Tokens
+// become leaves with their text, Trivia is dropped.
+func ToTree(red *Red, factory NodeFactory) (parser.INode, error) {
+	var text string
+	if red.IsToken() {
+		text = red.Text()
+	}
+	node := factory(red.Kind(), text, parser.Bracket{StartOffset: red.Offset(), Length: red.Width()})
+	if node == nil {
+		return nil, fmt.Errorf("cst: no node for kind %q", red.Kind())
+	}
+	for _, child := range red.Children() {
+		if child.Kind() == Trivia {
+			continue
+		}
+		converted, err := ToTree(child, factory)
+		if err != nil {
+			return nil, err
+		}
+		if err := parser.AppendChild(node, converted); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mtrense/parsertk/parser"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// DOT writes the tree below root as Graphviz digraph.
+func DOT(w io.Writer, root parser.INode, options Options) error {
+	nodes, tokens := layout(root, options)
+	out := bufio.NewWriter(w)
+	out.WriteString("digraph tree {\n")
+	out.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	for _, n := range nodes {
+		attributes := fmt.Sprintf(`label="%s"`, dotEscaper.Replace(n.label))
+		if n.highlighted {
+			attributes += fmt.Sprintf(`, style=filled, fillcolor="%s"`, options.color())
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", n.id, attributes)
+	}
+	for _, n := range nodes {
+		if n.parent != "" {
+			fmt.Fprintf(out, "  %s -> %s;\n", n.parent, n.id)
+		}
+	}
+	if len(tokens) > 0 {
+		out.WriteString("  subgraph tokens {\n")
+		out.WriteString("    rank=same;\n")
+		for _, tok := range tokens {
+			fmt.Fprintf(out, "    %s [label=\"%s\", shape=plaintext];\n", tok.id, dotEscaper.Replace(tok.label))
+		}
+		for i := 1; i < len(tokens); i++ {
+			fmt.Fprintf(out, "    %s -> %s [style=invis];\n", tokens[i-1].id, tokens[i].id)
+		}
+		out.WriteString("  }\n")
+		for _, tok := range tokens {
+			for _, leaf := range tok.leaves {
+				fmt.Fprintf(out, "  %s -> %s [style=dashed, arrowhead=none];\n", leaf, tok.id)
+			}
+		}
+	}
+	out.WriteString("}\n")
+	return out.Flush()
+}
@@ -0,0 +1,126 @@
+// Package graph renders INode trees as Graphviz DOT and Mermaid flowcharts,
+// optionally with the token stream beneath the tree.
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+	"github.com/mtrense/parsertk/parser"
+)
+
+// Field selects a part of the default node label.
+type Field int
+
+const (
+	TypeField Field = 1 << iota
+	ValueField
+	SpanField
+)
+
+// Options adjust the rendered graph.
+type Options struct {
+	// Fields selects the parts of the node labels, one per line. Zero means
+	// TypeField|ValueField.
+	Fields Field
+	// Label, if set, replaces the default node labels.
+	Label func(node parser.INode) string
+	// Tokens, if set, are drawn in a row beneath the tree, each connected to
+	// the leaves whose span contains it.
+	Tokens []lexer.Token
+	// Highlight, if set, is the root of a subtree drawn highlighted.
+	Highlight parser.INode
+	// Color is the fill color of highlighted nodes. Defaults to #ffe08a.
+	Color string
+}
+
+// Label builds the default label of node from fields.
+func Label(node parser.INode, fields Field) string {
+	var lines []string
+	if fields&TypeField != 0 && node.NodeType() != "" {
+		lines = append(lines, node.NodeType())
+	}
+	if fields&ValueField != 0 && node.String() != "" {
+		lines = append(lines, node.String())
+	}
+	if fields&SpanField != 0 {
+		bracket := node.Bracket()
+		lines = append(lines, fmt.Sprintf("%d..%d", bracket.StartOffset, bracket.StartOffset+bracket.Length))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type graphNode struct {
+	id          string
+	label       string
+	parent      string
+	highlighted bool
+	node        parser.INode
+}
+
+type graphToken struct {
+	id     string
+	label  string
+	leaves []string
+}
+
+// layout numbers the nodes in pre-order and resolves labels and token links.
+func layout(root parser.INode, options Options) ([]graphNode, []graphToken) {
+	label := options.Label
+	if label == nil {
+		fields := options.Fields
+		if fields == 0 {
+			fields = TypeField | ValueField
+		}
+		label = func(node parser.INode) string {
+			return Label(node, fields)
+		}
+	}
+	var nodes []graphNode
+	ids := make(map[parser.INode]string)
+	highlightDepth := -1
+	parser.Walk(root, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		if highlightDepth >= len(path) {
+			highlightDepth = -1
+		}
+		if highlightDepth < 0 && options.Highlight != nil && node == options.Highlight {
+			highlightDepth = len(path)
+		}
+		id := fmt.Sprintf("n%d", len(nodes))
+		ids[node] = id
+		n := graphNode{id: id, label: label(node), highlighted: highlightDepth >= 0, node: node}
+		if len(path) > 1 {
+			n.parent = ids[path[len(path)-2]]
+		}
+		nodes = append(nodes, n)
+		return parser.Continue
+	}, nil)
+	tokens := make([]graphToken, len(options.Tokens))
+	for i, tok := range options.Tokens {
+		tokens[i] = graphToken{id: fmt.Sprintf("t%d", i), label: string(tok.Typ)}
+		if tok.Value != "" {
+			tokens[i].label += "\n" + tok.Value
+		}
+		for _, n := range nodes {
+			if n.node.IsLeaf() && contains(n.node.Bracket(), tok) {
+				tokens[i].leaves = append(tokens[i].leaves, n.id)
+			}
+		}
+	}
+	return nodes, tokens
+}
+
+func contains(bracket parser.Bracket, tok lexer.Token) bool {
+	if bracket.Length == 0 {
+		return tok.Offset == bracket.StartOffset
+	}
+	return tok.Offset >= bracket.StartOffset && tok.Offset < bracket.StartOffset+bracket.Length
+}
+
+func (s Options) color() string {
+	if s.Color != "" {
+		return s.Color
+	}
+	return "#ffe08a"
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"bytes"
+
+	"github.com/mtrense/parsertk/lexer"
+	"github.com/mtrense/parsertk/parser"
+	"github.com/mtrense/parsertk/parser/internal/testutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var n = testutil.Spanned
+
+// sample is the tree of (f "x")
+func sample() (parser.INode, parser.INode) {
+	arg := n("arg", "", 3, 3, n("string", `"x"`, 3, 3))
+	return n("call", "", 0, 7, n("ident", "f", 1, 1), arg), arg
+}
+
+var sampleTokens = []lexer.Token{
+	{Typ: "START", Value: "(", Offset: 0},
+	{Typ: "SYMBOL", Value: "f", Offset: 1},
+	{Typ: "STRING", Value: `"x"`, Offset: 3},
+	{Typ: "END", Value: ")", Offset: 6},
+}
+
+func render(format func(w *bytes.Buffer) error) string {
+	var buffer bytes.Buffer
+	Expect(format(&buffer)).To(Succeed())
+	return buffer.String()
+}
+
+var _ = Describe("Label", func() {
+	It("joins the selected fields", func() {
+		node := n("ident", "f", 1, 1)
+		Expect(Label(node, TypeField|ValueField|SpanField)).To(Equal("ident\nf\n1..2"))
+		Expect(Label(node, SpanField)).To(Equal("1..2"))
+	})
+})
+
+var _ = Describe("DOT", func() {
+	It("renders the tree", func() {
+		root, _ := sample()
+		Expect(render(func(w *bytes.Buffer) error { return DOT(w, root, Options{}) })).To(Equal(`digraph tree {
+  node [shape=box, fontname="monospace"];
+  n0 [label="call"];
+  n1 [label="ident\nf"];
+  n2 [label="arg"];
+  n3 [label="string\n\"x\""];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+}
+`))
+	})
+	It("renders tokens, custom labels and highlights", func() {
+		root, arg := sample()
+		Expect(render(func(w *bytes.Buffer) error {
+			return DOT(w, root, Options{
+				Label:     func(node parser.INode) string { return node.NodeType() },
+				Tokens:    sampleTokens,
+				Highlight: arg,
+			})
+		})).To(Equal(`digraph tree {
+  node [shape=box, fontname="monospace"];
+  n0 [label="call"];
+  n1 [label="ident"];
+  n2 [label="arg", style=filled, fillcolor="#ffe08a"];
+  n3 [label="string", style=filled, fillcolor="#ffe08a"];
+  n0 -> n1;
+  n0 -> n2;
+  n2 -> n3;
+  subgraph tokens {
+    rank=same;
+    t0 [label="START\n(", shape=plaintext];
+    t1 [label="SYMBOL\nf", shape=plaintext];
+    t2 [label="STRING\n\"x\"", shape=plaintext];
+    t3 [label="END\n)", shape=plaintext];
+    t0 -> t1 [style=invis];
+    t1 -> t2 [style=invis];
+    t2 -> t3 [style=invis];
+  }
+  n1 -> t1 [style=dashed, arrowhead=none];
+  n3 -> t2 [style=dashed, arrowhead=none];
+}
+`))
+	})
+	It("highlights only the selected subtree", func() {
+		root, _ := sample()
+		first := root.Children()[0]
+		output := render(func(w *bytes.Buffer) error { return DOT(w, root, Options{Highlight: first, Color: "red"}) })
+		Expect(output).To(ContainSubstring(`n1 [label="ident\nf", style=filled, fillcolor="red"];`))
+		Expect(output).To(ContainSubstring(`n2 [label="arg"];`))
+	})
+})
+
+var _ = Describe("Mermaid", func() {
+	It("renders the tree", func() {
+		root, arg := sample()
+		Expect(render(func(w *bytes.Buffer) error {
+			return Mermaid(w, root, Options{Fields: TypeField | SpanField, Tokens: sampleTokens[1:3], Highlight: arg})
+		})).To(Equal(`flowchart TD
+  n0["call<br/>0..7"]
+  n1["ident<br/>1..2"]
+  n2["arg<br/>3..6"]
+  n3["string<br/>3..6"]
+  n0 --> n1
+  n0 --> n2
+  n2 --> n3
+  subgraph tokens [" "]
+    direction LR
+    t0(["SYMBOL<br/>f"])
+    t1(["STRING<br/>#quot;x#quot;"])
+    t0 ~~~ t1
+  end
+  n1 -.- t0
+  n3 -.- t1
+  classDef highlight fill:#ffe08a
+  class n2,n3 highlight
+`))
+	})
+	It("escapes labels", func() {
+		output := render(func(w *bytes.Buffer) error { return Mermaid(w, n("op", "<#>", 0, 1), Options{}) })
+		Expect(output).To(ContainSubstring(`n0["op<br/>#lt;#35;#gt;"]`))
+	})
+})
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mtrense/parsertk/parser"
+)
+
+var mermaidEscaper = strings.NewReplacer(`#`, `#35;`, `"`, `#quot;`, `<`, `#lt;`, `>`, `#gt;`, "\n", `<br/>`)
+
+// Mermaid writes the tree below root as Mermaid flowchart.
+func Mermaid(w io.Writer, root parser.INode, options Options) error {
+	nodes, tokens := layout(root, options)
+	out := bufio.NewWriter(w)
+	out.WriteString("flowchart TD\n")
+	var highlighted []string
+	for _, n := range nodes {
+		fmt.Fprintf(out, "  %s[\"%s\"]\n", n.id, mermaidEscaper.Replace(n.label))
+		if n.highlighted {
+			highlighted = append(highlighted, n.id)
+		}
+	}
+	for _, n := range nodes {
+		if n.parent != "" {
+			fmt.Fprintf(out, "  %s --> %s\n", n.parent, n.id)
+		}
+	}
+	if len(tokens) > 0 {
+		out.WriteString("  subgraph tokens [\" \"]\n")
+		out.WriteString("    direction LR\n")
+		for _, tok := range tokens {
+			fmt.Fprintf(out, "    %s([\"%s\"])\n", tok.id, mermaidEscaper.Replace(tok.label))
+		}
+		for i := 1; i < len(tokens); i++ {
+			fmt.Fprintf(out, "    %s ~~~ %s\n", tokens[i-1].id, tokens[i].id)
+		}
+		out.WriteString("  end\n")
+		for _, tok := range tokens {
+			for _, leaf := range tok.leaves {
+				fmt.Fprintf(out, "  %s -.- %s\n", leaf, tok.id)
+			}
+		}
+	}
+	if len(highlighted) > 0 {
+		fmt.Fprintf(out, "  classDef highlight fill:%s\n", options.color())
+		fmt.Fprintf(out, "  class %s highlight\n", strings.Join(highlighted, ","))
+	}
+	return out.Flush()
+}
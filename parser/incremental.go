@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"errors"
+)
+
+// ErrEdit is returned by Reparse for edits that do not fit the source or the
+// previous tree.
+var ErrEdit = errors.New("parser: edit does not fit the source")
+
+// Edit describes the replacement of OldLength runes at Offset by NewLength
+// runes.
+type Edit struct {
+	Offset    int
+	OldLength int
+	NewLength int
+}
+
+// ApplyEdit replaces length runes at offset of source by text and returns the
+// result along with the Edit describing it.
+func ApplyEdit(source string, offset, length int, text string) (string, Edit) {
+	runes := []rune(source)
+	inserted := []rune(text)
+	result := string(runes[:offset]) + text + string(runes[offset+length:])
+	return result, Edit{Offset: offset, OldLength: length, NewLength: len(inserted)}
+}
+
+// ParseFunc parses source into a tree whose root has the top-level items of
+// source as children.
+type ParseFunc func(source string) INode
+
+// Reparsed is the result of a Reparse.
+type Reparsed struct {
+	// Root is the root of the updated tree.
+	Root INode
+	// Changed are the newly parsed children of Root. Nodes below the top level
+	// are not reused, so a changed child is new as a whole.
+	Changed []INode
+	// Removed are the former children of Root that were replaced.
+	Removed []INode
+}
+
+// Reparser updates trees after edits of their source, reparsing only the
+// top-level items touched by an edit and reusing all others. Reuse is limited
+// to the top level: an item touched by an edit is parsed again as a whole, so
+// editing inside a large item costs as much as parsing it. It relies on
+// top-level items starting and ending in the initial state of the lexer and
+// the parser, on their brackets covering all their tokens and on the source
+// between them being insignificant. All nodes must embed Node.
+type Reparser struct {
+	parse ParseFunc
+}
+
+func NewReparser(parse ParseFunc) *Reparser {
+	return &Reparser{
+		parse: parse,
+	}
+}
+
+// Reparse updates previous, the tree of the source before edit, to the tree
+// of source, which is the result of edit. The items touched by the edit are
+// parsed again, along with following items until the new parse yields an item
+// equal to an old one at the same position, from which on the old items are
+// reused with their offsets shifted, which visits every node following the
+// edit. previous is modified in place and its bracket is set to end with its
+// last child. Reparse returns ErrEdit if the edit lies outside of source or
+// the previous tree extends beyond the source before the edit.
+func (s *Reparser) Reparse(previous INode, source string, edit Edit) (Reparsed, error) {
+	root := nodeOf(previous)
+	if root == nil {
+		return Reparsed{}, ErrForeign
+	}
+	text := []rune(source)
+	children := append([]INode(nil), previous.Children()...)
+	delta := edit.NewLength - edit.OldLength
+	editEnd := edit.Offset + edit.OldLength
+	if edit.Offset < 0 || edit.OldLength < 0 || edit.NewLength < 0 || edit.Offset+edit.NewLength > len(text) ||
+		len(children) > 0 && end(children[len(children)-1]) > len(text)-delta {
+		return Reparsed{}, ErrEdit
+	}
+
+	// Items [first, last) touch the edit.
+	first := 0
+	for first < len(children) && end(children[first]) < edit.Offset {
+		first++
+	}
+	// The last item may be left open at the end of the source, so edits after
+	// it may extend it.
+	if first == len(children) && first > 0 {
+		first--
+	}
+	last := first
+	for last < len(children) && children[last].Bracket().StartOffset <= editEnd {
+		last++
+	}
+	start := 0
+	if first < last && children[first].Bracket().StartOffset <= edit.Offset {
+		start = children[first].Bracket().StartOffset
+	} else if first > 0 {
+		start = end(children[first-1])
+	}
+
+	// Parse ever more following items until the parse is in sync with the old
+	// tree again.
+	var parsed []INode
+	sync := len(children)
+	for lookahead := 1; ; lookahead *= 2 {
+		limit := last + lookahead
+		regionEnd := len(text)
+		if limit < len(children) {
+			regionEnd = end(children[limit-1]) + delta
+		}
+		parsed = s.parse(string(text[start:regionEnd])).Children()
+		for _, node := range parsed {
+			shift(node, start)
+		}
+		if limit >= len(children) {
+			if i, j := s.resync(parsed, children[last:], delta); i >= 0 {
+				parsed, sync = parsed[:i], last+j
+			}
+			break
+		}
+		if i, j := s.resync(parsed, children[last:limit], delta); i >= 0 {
+			parsed, sync = parsed[:i], last+j
+			break
+		}
+	}
+
+	if err := InsertChild(previous, first, parsed...); err != nil {
+		return Reparsed{}, err
+	}
+	for _, node := range children[first:sync] {
+		Detach(node)
+	}
+	for _, node := range children[sync:] {
+		shift(node, delta)
+	}
+	root.ExtendTo(root.bracket.StartOffset)
+	if len(root.children) > 0 {
+		root.ExtendTo(end(root.children[len(root.children)-1]))
+	}
+	return Reparsed{
+		Root:    previous,
+		Changed: parsed,
+		Removed: children[first:sync],
+	}, nil
+}
+
+// resync finds the first parsed item equal to one of the old items shifted by
+// delta and returns both their indices, or -1 if there is none.
+func (s *Reparser) resync(parsed, old []INode, delta int) (int, int) {
+	byOffset := make(map[int]int, len(old))
+	for j, node := range old {
+		byOffset[node.Bracket().StartOffset+delta] = j
+	}
+	for i, node := range parsed {
+		if j, ok := byOffset[node.Bracket().StartOffset]; ok && equal(node, old[j], delta) {
+			return i, j
+		}
+	}
+	return -1, -1
+}
+
+// equal reports whether the trees below a and b are equal, with the offsets of
+// b shifted by delta.
+func equal(a, b INode, delta int) bool {
+	ab, bb := a.Bracket(), b.Bracket()
+	if a.NodeType() != b.NodeType() || a.String() != b.String() ||
+		ab.StartOffset != bb.StartOffset+delta || ab.Length != bb.Length ||
+		len(a.Children()) != len(b.Children()) {
+		return false
+	}
+	for i, child := range a.Children() {
+		if !equal(child, b.Children()[i], delta) {
+			return false
+		}
+	}
+	return true
+}
+
+// shift moves the brackets of all nodes below root by delta.
+func shift(root INode, delta int) {
+	if delta == 0 {
+		return
+	}
+	Walk(root, func(node INode, path []INode) WalkAction {
+		nodeOf(node).bracket.StartOffset += delta
+		return Continue
+	}, nil)
+}
+
+func end(node INode) int {
+	bracket := node.Bracket()
+	return bracket.StartOffset + bracket.Length
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// generateForms builds count top-level S-expressions and returns them along
+// with the offset of the form in the middle.
+func generateForms(count int) (string, int) {
+	var b strings.Builder
+	middle := 0
+	for i := 0; i < count; i++ {
+		if i == count/2 {
+			middle = utf8.RuneCountInString(b.String())
+		}
+		fmt.Fprintf(&b, "(define (f%d x) (+ x %d)) ; form %d\n", i, i, i)
+	}
+	return b.String(), middle
+}
+
+// benchmarkKeystrokes types and deletes a rune at offset of source, reparsing
+// after each keystroke.
+func benchmarkKeystrokes(b *testing.B, source string, offset int) {
+	b.ReportAllocs()
+	reparser := NewReparser(parseSexp)
+	root := parseSexp(source)
+	b.SetBytes(int64(len(source)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var edit Edit
+		if i%2 == 0 {
+			source, edit = ApplyEdit(source, offset, 0, "y")
+		} else {
+			source, edit = ApplyEdit(source, offset, 1, "")
+		}
+		result, err := reparser.Reparse(root, source, edit)
+		if err != nil {
+			b.Fatal(err)
+		}
+		root = result.Root
+	}
+}
+
+func BenchmarkParseForms(b *testing.B) {
+	b.ReportAllocs()
+	source, _ := generateForms(5000)
+	b.SetBytes(int64(len(source)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parseSexp(source)
+	}
+}
+
+// BenchmarkReparseTopLevel edits one of many top-level forms, which is all
+// Reparse parses again.
+func BenchmarkReparseTopLevel(b *testing.B) {
+	source, middle := generateForms(5000)
+	benchmarkKeystrokes(b, source, middle+1)
+}
+
+// BenchmarkReparseNested edits the same form nested in a single top-level
+// form, which Reparse parses again as a whole.
+func BenchmarkReparseNested(b *testing.B) {
+	source, middle := generateForms(5000)
+	benchmarkKeystrokes(b, "(\n"+source+")", middle+3)
+}
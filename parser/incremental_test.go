@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"bytes"
+	"math/rand"
+	"unicode/utf8"
+
+	"github.com/mtrense/parsertk/lexer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var sexpTokens = lexer.MustCompileRegexSet(
+	lexer.Regex("WS", `\s+`),
+	lexer.Regex("COMMENT", `;[^\n]*`),
+	lexer.Regex("OPEN", `\(`),
+	lexer.Regex("CLOSE", `\)`),
+	lexer.Regex("STRING", `"[^"]*"`),
+	lexer.Regex("SYMBOL", `[^\s();"]+`),
+)
+
+// link appends child to parent, panicking on errors so parseSexp serves
+// benchmarks as well.
+func link(parent, child INode) {
+	if err := AppendChild(parent, child); err != nil {
+		panic(err)
+	}
+}
+
+// extend moves the ends of node and its ancestors to offset.
+func extend(node INode, offset int) {
+	for ; node != nil; node = node.Parent() {
+		node.(*namedNode).ExtendTo(offset)
+	}
+}
+
+// parseSexp parses S-expressions into namedNodes named by their text, closing
+// lists left open at the end of the source after their last token.
+func parseSexp(source string) INode {
+	root := &namedNode{name: "root"}
+	p := NewParser(root)
+	leaf := func(cn INode, tok lexer.Token) INode {
+		end := tok.Offset + utf8.RuneCountInString(tok.Value)
+		leaf := &namedNode{Node: NewNodeAt(nil, Bracket{tok.Offset, end - tok.Offset}), name: tok.Value}
+		link(cn, leaf)
+		extend(cn, end)
+		return nil
+	}
+	p.RegisterFactory("WS", Ignore).
+		RegisterFactory("COMMENT", Ignore).
+		RegisterFactory("EOF", Ignore).
+		RegisterFactory("ERR", func(cn INode, tok lexer.Token) INode {
+			// Lexing stops at errors, so the error covers the rest of the source.
+			tok.Value = string([]rune(source)[tok.Offset:])
+			return leaf(cn, tok)
+		}).
+		RegisterFactory("STRING", leaf).
+		RegisterFactory("SYMBOL", leaf).
+		RegisterFactory("OPEN", func(cn INode, tok lexer.Token) INode {
+			list := &namedNode{Node: NewNodeAt(nil, Bracket{tok.Offset, 1}), name: "list"}
+			link(cn, list)
+			extend(cn, tok.Offset+1)
+			return list
+		}).
+		RegisterFactory("CLOSE", func(cn INode, tok lexer.Token) INode {
+			if cn.IsRoot() {
+				return leaf(cn, tok)
+			}
+			extend(cn, tok.Offset+1)
+			return cn.Parent()
+		})
+	sexpTokens.Lex(lexer.SourceStringReader(source), p.Visit, "EOF", "ERR")
+	return root
+}
+
+func dump(root INode) string {
+	var out bytes.Buffer
+	Expect(NewTreePrinter().Spans().Print(&out, root)).To(Succeed())
+	Walk(root, func(node INode, path []INode) WalkAction {
+		if len(path) > 1 {
+			Expect(node.Parent()).To(BeIdenticalTo(path[len(path)-2]))
+		}
+		return Continue
+	}, nil)
+	return out.String()
+}
+
+var _ = Describe("Reparser", func() {
+	reparser := NewReparser(parseSexp)
+
+	It("reuses items outside the edit", func() {
+		source := "(a b) (c d) ; note\n(e f)"
+		root := parseSexp(source)
+		before := append([]INode(nil), root.Children()...)
+		source, edit := ApplyEdit(source, 8, 1, "xyz")
+		result, err := reparser.Reparse(root, source, edit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dump(result.Root)).To(Equal(dump(parseSexp(source))))
+		Expect(result.Root.Children()[0]).To(BeIdenticalTo(before[0]))
+		Expect(result.Root.Children()[2]).To(BeIdenticalTo(before[2]))
+		Expect(result.Root.Children()[2].Bracket()).To(Equal(Bracket{21, 5}))
+		Expect(result.Changed).To(HaveLen(1))
+		Expect(result.Removed).To(Equal(before[1:2]))
+	})
+	It("reparses items affected beyond the edit", func() {
+		source := `(a) b "c" (d)`
+		root := parseSexp(source)
+		source, edit := ApplyEdit(source, 4, 0, `"`)
+		result, err := reparser.Reparse(root, source, edit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dump(result.Root)).To(Equal(dump(parseSexp(source))))
+		Expect(result.Removed).To(HaveLen(3))
+	})
+	It("merges items joined by an edit", func() {
+		source := "a b c"
+		root := parseSexp(source)
+		source, edit := ApplyEdit(source, 1, 1, "")
+		result, err := reparser.Reparse(root, source, edit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dump(result.Root)).To(Equal(dump(parseSexp(source))))
+		Expect(result.Root.Children()).To(HaveLen(2))
+		Expect(result.Root.Children()[0].String()).To(Equal("ab"))
+	})
+	It("extends empty roots", func() {
+		root := parseSexp("")
+		source, edit := ApplyEdit("", 0, 0, "(a)")
+		result, err := reparser.Reparse(root, source, edit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Root.Bracket()).To(Equal(Bracket{0, 3}))
+	})
+	It("rejects edits not fitting the source", func() {
+		root := parseSexp("(a b)")
+		before := dump(root)
+		for _, edit := range []Edit{{4, 0, 3}, {-1, 0, 0}, {0, -1, 0}, {0, 0, -1}} {
+			_, err := reparser.Reparse(root, "(a b)", edit)
+			Expect(err).To(MatchError(ErrEdit), "%+v", edit)
+		}
+		_, err := reparser.Reparse(root, "(a)", Edit{0, 0, 0})
+		Expect(err).To(MatchError(ErrEdit))
+		Expect(dump(root)).To(Equal(before))
+	})
+	It("rejects nodes not embedding Node", func() {
+		_, err := NewReparser(func(string) INode {
+			return &namedNode{name: "root"}
+		}).Reparse(foreignNode{}, "a", Edit{0, 0, 1})
+		Expect(err).To(MatchError(ErrForeign))
+	})
+	It("matches full reparses on random edits", func() {
+		random := rand.New(rand.NewSource(1))
+		pieces := []string{"(", ")", "a", "bc", "é", " ", "\n", `"`, ";", "(x y)"}
+		for round := 0; round < 50; round++ {
+			var source string
+			for i := 0; i < 20; i++ {
+				source += pieces[random.Intn(len(pieces))]
+			}
+			root := parseSexp(source)
+			for step := 0; step < 20; step++ {
+				length := utf8.RuneCountInString(source)
+				offset := random.Intn(length + 1)
+				removed := random.Intn(length - offset + 1)
+				if removed > 3 {
+					removed = random.Intn(4)
+				}
+				var inserted string
+				for i := random.Intn(3); i > 0; i-- {
+					inserted += pieces[random.Intn(len(pieces))]
+				}
+				var edit Edit
+				source, edit = ApplyEdit(source, offset, removed, inserted)
+				result, err := reparser.Reparse(root, source, edit)
+				Expect(err).NotTo(HaveOccurred())
+				root = result.Root
+				Expect(dump(root)).To(Equal(dump(parseSexp(source))), "source %q after %+v", source, edit)
+			}
+		}
+	})
+})
@@ -0,0 +1,36 @@
+// Package testutil builds parse trees for the tests of the parser packages.
+package testutil
+
+import (
+	"github.com/mtrense/parsertk/parser"
+)
+
+// Node is a node with a fixed type and value.
+type Node struct {
+	parser.Node
+	Type  string
+	Value string
+}
+
+func (s *Node) NodeType() string {
+	return s.Type
+}
+
+func (s *Node) String() string {
+	return s.Value
+}
+
+// Tree returns a Node with children. It panics if they cannot be linked.
+func Tree(typ, value string, children ...parser.INode) parser.INode {
+	return Spanned(typ, value, 0, 0, children...)
+}
+
+// Spanned is like Tree for a Node covering length runes at start.
+func Spanned(typ, value string, start, length int, children ...parser.INode) parser.INode {
+	bracket := parser.Bracket{StartOffset: start, Length: length}
+	node := &Node{Node: parser.NewNodeAt(nil, bracket), Type: typ, Value: value}
+	if err := parser.AppendChild(node, children...); err != nil {
+		panic(err)
+	}
+	return node
+}
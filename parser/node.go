@@ -1,16 +1,29 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/mtrense/parsertk/lexer"
 	"strings"
 )
 
+var (
+	ErrCycle     = errors.New("parser: node would become its own ancestor")
+	ErrIndex     = errors.New("parser: child index out of range")
+	ErrNotChild  = errors.New("parser: node is not a child")
+	ErrRoot      = errors.New("parser: root node has no parent to be replaced in")
+	ErrDuplicate = errors.New("parser: node inserted more than once")
+	ErrForeign   = errors.New("parser: node does not embed parser.Node")
+)
+
 type Bracket struct {
 	StartOffset int
 	Length      int
 }
 
+// INode is a node of a parse tree. Implementations embed Node, which provides
+// all methods but NodeType and String. Trees are changed with AppendChild,
+// InsertChild, RemoveChild, ReplaceWith, Detach and MoveTo.
 type INode interface {
 	Parent() INode
 	Root() INode
@@ -28,6 +41,7 @@ type Node struct {
 	parent   INode
 	children []INode
 	bracket  Bracket
+	self     INode
 }
 
 func NewNode(parent INode, tok lexer.Token) Node {
@@ -38,6 +52,15 @@ func NewNode(parent INode, tok lexer.Token) Node {
 	}
 }
 
+// NewNodeAt is like NewNode for nodes that do not stem from a single token.
+func NewNodeAt(parent INode, bracket Bracket) Node {
+	return Node{
+		parent:   parent,
+		children: make([]INode, 0),
+		bracket:  bracket,
+	}
+}
+
 func (s *Node) Parent() INode {
 	return s.parent
 }
@@ -46,11 +69,13 @@ func (s *Node) IsRoot() bool {
 	return s.parent == nil
 }
 
+// Root returns the topmost ancestor, or the node itself if it has no parent.
 func (s *Node) Root() INode {
-	if s.IsRoot() {
-		return s
+	root := s.this()
+	for !root.IsRoot() {
+		root = root.Parent()
 	}
-	return s.Parent().Root()
+	return root
 }
 
 func (s *Node) Depth() int {
@@ -72,6 +97,15 @@ func (s *Node) Bracket() Bracket {
 	return s.bracket
 }
 
+// ExtendTo moves the end of the bracket to offset. Offsets before the start
+// leave the bracket empty.
+func (s *Node) ExtendTo(offset int) {
+	if offset < s.bracket.StartOffset {
+		offset = s.bracket.StartOffset
+	}
+	s.bracket.Length = offset - s.bracket.StartOffset
+}
+
 func (s *Node) NodeType() string {
 	return ""
 }
@@ -80,8 +114,210 @@ func (s *Node) String() string {
 	return ""
 }
 
+// AddChild appends children like AppendChild, linking them to the node
+// embedding s once that has been passed to one of the mutation functions, and
+// panics where AppendChild returns an error.
+//
+// Deprecated: use AppendChild, which takes the node to link to explicitly and
+// returns errors.
 func (s *Node) AddChild(child ...INode) {
-	s.children = append(s.children, child...)
+	if err := AppendChild(s.this(), child...); err != nil {
+		panic(err)
+	}
+}
+
+// embedder is implemented by all types embedding Node.
+type embedder interface {
+	embedded() *Node
+}
+
+func (s *Node) embedded() *Node {
+	return s
+}
+
+// nodeOf returns the Node embedded by node, or nil.
+func nodeOf(node INode) *Node {
+	if e, ok := node.(embedder); ok {
+		return e.embedded()
+	}
+	return nil
+}
+
+// remember records node as the node embedding its Node, so that methods of
+// Node can return it, and returns that Node or nil.
+func remember(node INode) *Node {
+	n := nodeOf(node)
+	if n != nil {
+		n.self = node
+	}
+	return n
+}
+
+// this returns the node embedding s if it has been passed to one of the
+// mutation functions, or s. Copies of s do not inherit that node.
+func (s *Node) this() INode {
+	if s.self != nil && nodeOf(s.self) == s {
+		return s.self
+	}
+	return s
+}
+
+// same reports whether a and b are the same node, comparing the embedded Nodes
+// so a Node equals the node embedding it.
+func same(a, b INode) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if na, nb := nodeOf(a), nodeOf(b); na != nil && nb != nil {
+		return na == nb
+	}
+	return a == b
+}
+
+// The mutation functions below keep parent links and sibling order consistent.
+// They take the nodes to link explicitly, so children are linked to the node
+// passed as parent rather than to the Node it embeds. A node inserted
+// somewhere is first detached from its previous parent. All nodes involved
+// must embed Node.
+
+// AppendChild appends children to parent, see InsertChild.
+func AppendChild(parent INode, child ...INode) error {
+	p := nodeOf(parent)
+	if p == nil {
+		return ErrForeign
+	}
+	return InsertChild(parent, len(p.children), child...)
+}
+
+// InsertChild inserts children into parent before the child at index, or
+// appends them if index equals the number of children.
+func InsertChild(parent INode, index int, child ...INode) error {
+	p := remember(parent)
+	if p == nil {
+		return ErrForeign
+	}
+	if index < 0 || index > len(p.children) {
+		return ErrIndex
+	}
+	for i, c := range child {
+		n := nodeOf(c)
+		if n == nil {
+			return ErrForeign
+		}
+		for _, other := range child[:i] {
+			if nodeOf(other) == n {
+				return ErrDuplicate
+			}
+		}
+		for ancestor := parent; ancestor != nil; ancestor = ancestor.Parent() {
+			if nodeOf(ancestor) == n {
+				return ErrCycle
+			}
+		}
+	}
+	for _, c := range child {
+		if same(c.Parent(), parent) {
+			if position := p.indexOf(c); position >= 0 && position < index {
+				index--
+			}
+		}
+		Detach(c)
+	}
+	if index == len(p.children) {
+		// Appending leaves slices returned by Children before intact.
+		p.children = append(p.children, child...)
+	} else {
+		children := make([]INode, 0, len(p.children)+len(child))
+		children = append(children, p.children[:index]...)
+		children = append(children, child...)
+		children = append(children, p.children[index:]...)
+		p.children = children
+	}
+	for _, c := range child {
+		remember(c).parent = parent
+	}
+	return nil
+}
+
+// RemoveChild detaches child from parent.
+func RemoveChild(parent, child INode) error {
+	p := nodeOf(parent)
+	if p == nil {
+		return ErrForeign
+	}
+	if child == nil || !same(child.Parent(), parent) || p.indexOf(child) < 0 {
+		return ErrNotChild
+	}
+	Detach(child)
+	return nil
+}
+
+// ReplaceWith puts nodes in place of node in its parent and detaches node,
+// unless it is one of nodes.
+func ReplaceWith(node INode, nodes ...INode) error {
+	parent := node.Parent()
+	if parent == nil {
+		return ErrRoot
+	}
+	p := nodeOf(parent)
+	if p == nil || nodeOf(node) == nil {
+		return ErrForeign
+	}
+	index := p.indexOf(node)
+	Detach(node)
+	if err := InsertChild(parent, index, nodes...); err != nil {
+		InsertChild(parent, index, node)
+		return err
+	}
+	return nil
+}
+
+// Detach removes node from its parent, making it a root.
+func Detach(node INode) {
+	n := remember(node)
+	if n == nil || n.parent == nil {
+		return
+	}
+	if p := nodeOf(n.parent); p != nil {
+		if index := p.indexOf(node); index >= 0 {
+			p.children = append(p.children[:index:index], p.children[index+1:]...)
+		}
+	}
+	n.parent = nil
+}
+
+// MoveTo inserts node into parent at index, counted after node has been
+// detached.
+func MoveTo(node, parent INode, index int) error {
+	n := nodeOf(node)
+	if n == nil || nodeOf(parent) == nil {
+		return ErrForeign
+	}
+	for ancestor := parent; ancestor != nil; ancestor = ancestor.Parent() {
+		if nodeOf(ancestor) == n {
+			return ErrCycle
+		}
+	}
+	children := len(parent.Children())
+	if same(n.parent, parent) {
+		children--
+	}
+	if index < 0 || index > children {
+		return ErrIndex
+	}
+	Detach(node)
+	return InsertChild(parent, index, node)
+}
+
+// indexOf returns the position of child among the children of s, or -1.
+func (s *Node) indexOf(child INode) int {
+	n := nodeOf(child)
+	for i, c := range s.children {
+		if nodeOf(c) == n {
+			return i
+		}
+	}
+	return -1
 }
 
 func DumpTree(node INode) {
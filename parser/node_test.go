@@ -88,4 +88,128 @@ var _ = Describe("Node", func() {
 		Expect(child2.IsLeaf()).To(BeTrue())
 		Expect(child2.Parent()).To(Equal(subject))
 	})
+	It("extends its bracket", func() {
+		subject := NewNodeAt(nil, Bracket{StartOffset: 2, Length: 1})
+		subject.ExtendTo(5)
+		Expect(subject.Bracket()).To(Equal(Bracket{StartOffset: 2, Length: 3}))
+		subject.ExtendTo(1)
+		Expect(subject.Bracket()).To(Equal(Bracket{StartOffset: 2, Length: 0}))
+	})
+})
+
+// foreignNode implements INode without embedding Node.
+type foreignNode struct {
+	INode
+}
+
+var _ = Describe("Node mutation", func() {
+	var a, b, c, d INode
+	BeforeEach(func() {
+		b, c, d = tree("b"), tree("c"), tree("d")
+		a = tree("a", b, c)
+	})
+	It("links children to the embedding node", func() {
+		Expect(b.Parent()).To(BeIdenticalTo(a))
+		Expect(b.Root()).To(BeIdenticalTo(a))
+		Expect(a.Root()).To(BeIdenticalTo(a))
+		Expect(b.Depth()).To(Equal(1))
+	})
+	It("does not return the embedding node for copies", func() {
+		copied := *a.(*namedNode)
+		Expect(copied.Root()).To(BeIdenticalTo(&copied.Node))
+	})
+	It("rejects nodes not embedding Node", func() {
+		Expect(AppendChild(a, foreignNode{})).To(MatchError(ErrForeign))
+		Expect(AppendChild(foreignNode{}, d)).To(MatchError(ErrForeign))
+		Expect(shape(a)).To(Equal("(a b c)"))
+	})
+	It("links children added with AddChild", func() {
+		a.AddChild(d)
+		Expect(shape(a)).To(Equal("(a b c d)"))
+		Expect(d.Parent()).To(BeIdenticalTo(a))
+		Expect(func() { b.AddChild(a) }).To(PanicWith(ErrCycle))
+	})
+	It("inserts children at an index", func() {
+		Expect(InsertChild(a, 1, d)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a b d c)"))
+		Expect(InsertChild(a, 0, tree("x"), tree("y"))).To(Succeed())
+		Expect(shape(a)).To(Equal("(a x y b d c)"))
+	})
+	It("rejects indices out of range", func() {
+		Expect(InsertChild(a, 3, d)).To(MatchError(ErrIndex))
+		Expect(InsertChild(a, -1, d)).To(MatchError(ErrIndex))
+		Expect(d.IsRoot()).To(BeTrue())
+	})
+	It("moves children from their previous parent", func() {
+		Expect(AppendChild(d, b)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a c)"))
+		Expect(shape(d)).To(Equal("(d b)"))
+	})
+	It("reorders children of the same parent", func() {
+		Expect(AppendChild(a, b)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a c b)"))
+		Expect(InsertChild(a, 2, c)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a b c)"))
+	})
+	It("rejects cycles", func() {
+		Expect(AppendChild(b, a)).To(MatchError(ErrCycle))
+		Expect(AppendChild(a, a)).To(MatchError(ErrCycle))
+		Expect(MoveTo(a, b, 0)).To(MatchError(ErrCycle))
+		Expect(shape(a)).To(Equal("(a b c)"))
+		Expect(a.IsRoot()).To(BeTrue())
+	})
+	It("rejects duplicates", func() {
+		Expect(AppendChild(a, d, d)).To(MatchError(ErrDuplicate))
+		Expect(shape(a)).To(Equal("(a b c)"))
+	})
+	It("removes children", func() {
+		Expect(RemoveChild(a, b)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a c)"))
+		Expect(b.IsRoot()).To(BeTrue())
+		Expect(RemoveChild(a, b)).To(MatchError(ErrNotChild))
+		Expect(RemoveChild(a, d)).To(MatchError(ErrNotChild))
+	})
+	It("keeps previously returned children intact", func() {
+		children := a.Children()
+		Detach(b)
+		Expect(names(children)).To(Equal([]string{"b", "c"}))
+	})
+	It("replaces nodes", func() {
+		Expect(ReplaceWith(b, d, tree("e"))).To(Succeed())
+		Expect(shape(a)).To(Equal("(a d e c)"))
+		Expect(b.IsRoot()).To(BeTrue())
+	})
+	It("replaces nodes by themselves and others", func() {
+		Expect(ReplaceWith(c, d, c)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a b d c)"))
+	})
+	It("deletes nodes replaced by nothing", func() {
+		Expect(ReplaceWith(b)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a c)"))
+	})
+	It("does not replace roots", func() {
+		Expect(ReplaceWith(a, d)).To(MatchError(ErrRoot))
+	})
+	It("restores the tree when a replacement fails", func() {
+		Expect(ReplaceWith(b, a)).To(MatchError(ErrCycle))
+		Expect(shape(a)).To(Equal("(a b c)"))
+	})
+	It("detaches nodes", func() {
+		Detach(c)
+		Expect(c.IsRoot()).To(BeTrue())
+		Expect(shape(a)).To(Equal("(a b)"))
+		Detach(c)
+		Expect(c.IsRoot()).To(BeTrue())
+	})
+	It("moves nodes", func() {
+		Expect(MoveTo(c, a, 0)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a c b)"))
+		Expect(MoveTo(c, a, 1)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a b c)"))
+		Expect(MoveTo(c, a, 2)).To(MatchError(ErrIndex))
+		Expect(MoveTo(b, d, 0)).To(Succeed())
+		Expect(shape(a)).To(Equal("(a c)"))
+		Expect(shape(d)).To(Equal("(d b)"))
+		Expect(b.Root()).To(BeIdenticalTo(d))
+	})
 })
@@ -19,6 +19,7 @@ var Ignore NodeFactory = func(cn INode, tok lexer.Token) INode {
 }
 
 func NewParser(rootNode INode) *Parser {
+	remember(rootNode)
 	return &Parser{
 		rootNode:      rootNode,
 		currentNode:   rootNode,
@@ -0,0 +1,137 @@
+// Package pretty implements a Wadler-style document algebra for formatters.
+// Documents describe alternative layouts; Render picks for every group the
+// flat layout if it fits into the remaining width of the line and breaks all
+// its lines otherwise.
+package pretty
+
+import (
+	"unicode/utf8"
+)
+
+// Doc is a document, built from the constructors of this package.
+type Doc interface {
+	document()
+}
+
+type text string
+
+type line struct {
+	flat string
+	hard bool
+}
+
+type concat []Doc
+
+type nest struct {
+	indent int
+	doc    Doc
+}
+
+type align struct {
+	doc Doc
+}
+
+type group struct {
+	doc    Doc
+	broken bool
+}
+
+type fill []Doc
+
+// fillRest holds the items of a fill following one already laid out.
+type fillRest []Doc
+
+func (text) document()     {}
+func (line) document()     {}
+func (concat) document()   {}
+func (nest) document()     {}
+func (align) document()    {}
+func (group) document()    {}
+func (fill) document()     {}
+func (fillRest) document() {}
+
+var (
+	// Nil is the empty document.
+	Nil Doc = concat(nil)
+	// Line is a line break, or a space in a flat group.
+	Line Doc = line{flat: " "}
+	// SoftLine is a line break, or nothing in a flat group.
+	SoftLine Doc = line{}
+	// HardLine is always a line break. Groups containing it are never flat.
+	HardLine Doc = line{hard: true}
+)
+
+// Text is a literal string, which must not contain line breaks.
+func Text(s string) Doc {
+	return text(s)
+}
+
+// Concat lays out docs one after another.
+func Concat(docs ...Doc) Doc {
+	return concat(docs)
+}
+
+// Join lays out docs separated by sep.
+func Join(sep Doc, docs ...Doc) Doc {
+	result := make(concat, 0, 2*len(docs))
+	for i, doc := range docs {
+		if i > 0 {
+			result = append(result, sep)
+		}
+		result = append(result, doc)
+	}
+	return result
+}
+
+// Nest indents the lines broken in doc by indent more than the enclosing
+// document.
+func Nest(indent int, doc Doc) Doc {
+	return nest{indent: indent, doc: doc}
+}
+
+// Align indents the lines broken in doc to the column doc starts at.
+func Align(doc Doc) Doc {
+	return align{doc: doc}
+}
+
+// Group lays out doc flat if it fits, and breaks all its lines otherwise.
+func Group(doc Doc) Doc {
+	return group{doc: doc, broken: hasHardLine(doc)}
+}
+
+// Fill lays out docs separated by Line, breaking only before those that do not
+// fit on the current line.
+func Fill(docs ...Doc) Doc {
+	return fill(docs)
+}
+
+func hasHardLine(doc Doc) bool {
+	switch d := doc.(type) {
+	case line:
+		return d.hard
+	case concat:
+		return anyHardLine(d)
+	case fill:
+		return anyHardLine(d)
+	case nest:
+		return hasHardLine(d.doc)
+	case align:
+		return hasHardLine(d.doc)
+	case group:
+		return d.broken
+	}
+	return false
+}
+
+func anyHardLine(docs []Doc) bool {
+	for _, doc := range docs {
+		if hasHardLine(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+func width(s string) int {
+	return utf8.RuneCountInString(s)
+}
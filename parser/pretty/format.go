@@ -0,0 +1,76 @@
+package pretty
+
+import (
+	"github.com/mtrense/parsertk/parser"
+)
+
+// Rule builds the document of node from the documents of its children.
+type Rule func(node parser.INode, children []Doc) Doc
+
+// DefaultRule is the Rule of nodes without rule: leaves become their String(),
+// other nodes the concatenation of their children.
+func DefaultRule(node parser.INode, children []Doc) Doc {
+	if len(children) == 0 {
+		return Text(node.String())
+	}
+	return Concat(children...)
+}
+
+// Formatter builds documents from trees using the Rule registered for the
+// type of each node. Rules registered for a Go type take precedence over those
+// registered for a NodeType().
+type Formatter struct {
+	rules parser.HandlerTable
+}
+
+func NewFormatter() *Formatter {
+	return &Formatter{
+		rules: parser.NewHandlerTable(),
+	}
+}
+
+// On registers rule for nodes whose NodeType() is nodeType.
+func (s *Formatter) On(nodeType string, rule Rule) *Formatter {
+	s.rules.On(nodeType, rule)
+	return s
+}
+
+// OnType registers rule for nodes of the same Go type as example.
+func (s *Formatter) OnType(example parser.INode, rule Rule) *Formatter {
+	s.rules.OnType(example, rule)
+	return s
+}
+
+// Otherwise replaces DefaultRule as rule for nodes no other rule is registered
+// for.
+func (s *Formatter) Otherwise(rule Rule) *Formatter {
+	s.rules.Otherwise(rule)
+	return s
+}
+
+func (s *Formatter) rule(node parser.INode) Rule {
+	if rule, ok := s.rules.Lookup(node).(Rule); ok && rule != nil {
+		return rule
+	}
+	return DefaultRule
+}
+
+// Format builds the document of the tree below root, which is empty for a nil
+// root.
+func (s *Formatter) Format(root parser.INode) Doc {
+	if root == nil {
+		return Nil
+	}
+	// frames collects the documents of the children of every node on the path.
+	frames := [][]Doc{nil}
+	parser.Walk(root, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		frames = append(frames, nil)
+		return parser.Continue
+	}, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		children := frames[len(frames)-1]
+		frames = frames[:len(frames)-1]
+		frames[len(frames)-1] = append(frames[len(frames)-1], s.rule(node)(node, children))
+		return parser.Continue
+	})
+	return frames[0][0]
+}
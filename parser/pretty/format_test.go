@@ -0,0 +1,40 @@
+package pretty
+
+import (
+	"github.com/mtrense/parsertk/parser"
+	"github.com/mtrense/parsertk/parser/internal/testutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var n = testutil.Tree
+
+var _ = Describe("Formatter", func() {
+	// (define (square x) (* x x))
+	tree := n("list", "",
+		n("symbol", "define"),
+		n("list", "", n("symbol", "square"), n("symbol", "x")),
+		n("list", "", n("symbol", "*"), n("symbol", "x"), n("symbol", "x")))
+	formatter := NewFormatter().On("list", func(node parser.INode, children []Doc) Doc {
+		return Group(Concat(Text("("), Align(Join(Line, children...)), Text(")")))
+	})
+
+	It("builds documents by node type", func() {
+		Expect(Pretty(formatter.Format(tree), 80)).To(Equal("(define (square x) (* x x))"))
+		Expect(Pretty(formatter.Format(tree), 20)).To(Equal("(define\n (square x)\n (* x x))"))
+	})
+	It("formats nil as the empty document", func() {
+		Expect(Pretty(formatter.Format(nil), 80)).To(Equal(""))
+	})
+	It("falls back to DefaultRule", func() {
+		Expect(Pretty(NewFormatter().Format(tree), 80)).To(Equal("definesquarex*xx"))
+	})
+	It("prefers rules for Go types", func() {
+		formatter := NewFormatter().On("symbol", func(node parser.INode, children []Doc) Doc {
+			return Text("s")
+		}).OnType(&testutil.Node{}, func(node parser.INode, children []Doc) Doc {
+			return Text("g")
+		})
+		Expect(Pretty(formatter.Format(tree), 80)).To(Equal("g"))
+	})
+})
@@ -0,0 +1,13 @@
+package pretty
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPretty(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pretty Suite")
+}
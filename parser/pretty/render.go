@@ -0,0 +1,171 @@
+package pretty
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+type mode int
+
+const (
+	breakMode mode = iota
+	flatMode
+)
+
+// command is a document pending to be laid out at indent in mode.
+type command struct {
+	indent int
+	mode   mode
+	doc    Doc
+}
+
+// Render writes doc laid out to fit into width columns, if possible.
+func Render(w io.Writer, doc Doc, width int) error {
+	out := bufio.NewWriter(w)
+	r := renderer{out: out, width: width}
+	r.render(doc)
+	return out.Flush()
+}
+
+// Pretty returns doc laid out to fit into width columns, if possible.
+func Pretty(doc Doc, width int) string {
+	var builder strings.Builder
+	Render(&builder, doc, width)
+	return builder.String()
+}
+
+type renderer struct {
+	out    *bufio.Writer
+	width  int
+	column int
+	// pending is the indentation of the current line, written lazily so empty
+	// lines carry no trailing whitespace.
+	pending int
+}
+
+func (s *renderer) write(str string) {
+	if str == "" {
+		return
+	}
+	if s.pending > 0 {
+		s.out.WriteString(strings.Repeat(" ", s.pending))
+		s.pending = 0
+	}
+	s.out.WriteString(str)
+	s.column += width(str)
+}
+
+func (s *renderer) newline(indent int) {
+	s.out.WriteString("\n")
+	s.column = indent
+	s.pending = indent
+}
+
+func (s *renderer) render(doc Doc) {
+	stack := []command{{mode: breakMode, doc: doc}}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch d := c.doc.(type) {
+		case text:
+			s.write(string(d))
+		case line:
+			if c.mode == flatMode && !d.hard {
+				s.write(d.flat)
+			} else {
+				s.newline(c.indent)
+			}
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, command{c.indent, c.mode, d[i]})
+			}
+		case nest:
+			stack = append(stack, command{c.indent + d.indent, c.mode, d.doc})
+		case align:
+			stack = append(stack, command{s.column, c.mode, d.doc})
+		case group:
+			flat := command{c.indent, flatMode, d.doc}
+			if c.mode == breakMode && (d.broken || !s.fits(stack, flat)) {
+				flat.mode = breakMode
+			}
+			stack = append(stack, flat)
+		case fill:
+			if c.mode == flatMode {
+				stack = append(stack, command{c.indent, flatMode, Join(Line, d...)})
+			} else if len(d) > 0 {
+				first := command{c.indent, flatMode, d[0]}
+				if !s.fits(nil, first) {
+					first.mode = breakMode
+				}
+				stack = append(stack, command{c.indent, breakMode, fillRest(d[1:])}, first)
+			}
+		case fillRest:
+			if len(d) == 0 {
+				continue
+			}
+			next := command{c.indent, flatMode, d[0]}
+			separator := command{c.indent, flatMode, Line}
+			if !s.fits(nil, separator, next) {
+				next.mode = breakMode
+				separator.mode = breakMode
+			}
+			stack = append(stack, command{c.indent, breakMode, fillRest(d[1:])}, next, separator)
+		}
+	}
+}
+
+// fits reports whether the commands, followed by those on rest, can be laid
+// out up to the next line break without exceeding the width.
+func (s *renderer) fits(rest []command, commands ...command) bool {
+	remaining := s.width - s.column
+	var stack []command
+	for i := len(commands) - 1; i >= 0; i-- {
+		stack = append(stack, commands[i])
+	}
+	for remaining >= 0 {
+		if len(stack) == 0 {
+			if len(rest) == 0 {
+				return true
+			}
+			stack = append(stack, rest[len(rest)-1])
+			rest = rest[:len(rest)-1]
+		}
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch d := c.doc.(type) {
+		case text:
+			remaining -= width(string(d))
+		case line:
+			if c.mode == breakMode || d.hard {
+				return true
+			}
+			remaining -= width(d.flat)
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, command{c.indent, c.mode, d[i]})
+			}
+		case nest:
+			stack = append(stack, command{c.indent, c.mode, d.doc})
+		case align:
+			stack = append(stack, command{c.indent, c.mode, d.doc})
+		case group:
+			m := c.mode
+			if d.broken {
+				m = breakMode
+			}
+			stack = append(stack, command{c.indent, m, d.doc})
+		case fill:
+			if c.mode == flatMode {
+				stack = append(stack, command{c.indent, flatMode, Join(Line, d...)})
+			} else if len(d) > 0 {
+				stack = append(stack, command{c.indent, breakMode, fillRest(d[1:])}, command{c.indent, breakMode, d[0]})
+			}
+		case fillRest:
+			if len(d) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
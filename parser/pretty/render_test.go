@@ -0,0 +1,50 @@
+package pretty
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// call is f(args...) with the arguments broken onto indented lines if needed.
+func call(name string, args ...Doc) Doc {
+	return Group(Concat(Text(name+"("), Nest(2, Concat(SoftLine, Join(Concat(Text(","), Line), args...))), SoftLine, Text(")")))
+}
+
+var _ = Describe("Render", func() {
+	doc := call("f", Text("alpha"), call("g", Text("beta"), Text("gamma")), Text("delta"))
+
+	It("lays out groups flat if they fit", func() {
+		Expect(Pretty(doc, 80)).To(Equal("f(alpha, g(beta, gamma), delta)"))
+	})
+	It("breaks outer groups first", func() {
+		Expect(Pretty(doc, 20)).To(Equal("f(\n  alpha,\n  g(beta, gamma),\n  delta\n)"))
+		Expect(Pretty(doc, 10)).To(Equal("f(\n  alpha,\n  g(\n    beta,\n    gamma\n  ),\n  delta\n)"))
+	})
+	It("takes the text after a group into account", func() {
+		doc := Concat(Group(Concat(Text("a"), Line, Text("b"))), Text("-tail"))
+		Expect(Pretty(doc, 8)).To(Equal("a b-tail"))
+		Expect(Pretty(doc, 7)).To(Equal("a\nb-tail"))
+	})
+	It("never lays out hard lines flat", func() {
+		doc := Group(Concat(Text("{"), Nest(2, Concat(Line, Text("a;"), HardLine, Text("b;"))), Line, Text("}")))
+		Expect(Pretty(doc, 80)).To(Equal("{\n  a;\n  b;\n}"))
+	})
+	It("aligns to the current column", func() {
+		doc := Concat(Text("let x = "), Align(Join(HardLine, Text("1"), Text("2"))))
+		Expect(Pretty(doc, 80)).To(Equal("let x = 1\n        2"))
+	})
+	It("writes no indentation on empty lines", func() {
+		doc := Nest(2, Concat(Text("a"), HardLine, HardLine, Text("b")))
+		Expect(Pretty(doc, 80)).To(Equal("a\n\n  b"))
+	})
+	It("fills lines", func() {
+		words := []Doc{Text("one"), Text("two"), Text("three"), Text("four"), Text("five")}
+		Expect(Pretty(Fill(words...), 80)).To(Equal("one two three four five"))
+		Expect(Pretty(Fill(words...), 10)).To(Equal("one two\nthree four\nfive"))
+		Expect(Pretty(Group(Fill(words...)), 10)).To(Equal("one two\nthree four\nfive"))
+	})
+	It("counts runes", func() {
+		doc := Group(Concat(Text("ääää"), Line, Text("b")))
+		Expect(Pretty(doc, 6)).To(Equal("ääää b"))
+	})
+})
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+)
+
+const (
+	tokenWhitespace lexer.TokenType = "WS"
+	tokenIdent      lexer.TokenType = "IDENT"
+	tokenString     lexer.TokenType = "STRING"
+	tokenOperator   lexer.TokenType = "OP"
+	tokenPunct      lexer.TokenType = "PUNCT"
+	tokenEOF        lexer.TokenType = "EOF"
+	tokenError      lexer.TokenType = "ERR"
+)
+
+var queryTokens = lexer.MustCompileRegexSet(
+	lexer.Regex(tokenWhitespace, `\s+`),
+	lexer.Regex(tokenIdent, `[\pL\pN_\-.]+`),
+	lexer.Regex(tokenString, `"([^"\\]|\\.)*"|'([^'\\]|\\.)*'`),
+	lexer.Regex(tokenOperator, `\^=|\$=|\*=|~=|!=|=`),
+	lexer.Regex(tokenPunct, `[>,\[\]():@*]`),
+)
+
+// tokenize splits a query into tokens, ending with an EOF token.
+func tokenize(source string) ([]lexer.Token, error) {
+	var tokens []lexer.Token
+	queryTokens.Lex(lexer.SourceStringReader(source), func(tok lexer.Token) {
+		tokens = append(tokens, tok)
+	}, tokenEOF, tokenError)
+	if last := tokens[len(tokens)-1]; last.Typ == tokenError {
+		return nil, fmt.Errorf("query: unexpected character at offset %d", last.Offset)
+	}
+	return tokens, nil
+}
+
+// unquote removes the quotes of a string token and resolves backslash escapes
+// of quotes and backslashes. Other escapes are kept, so regular expressions
+// can be written naturally.
+func unquote(value string) string {
+	quote := value[:1]
+	inner := value[1 : len(value)-1]
+	return strings.NewReplacer(`\`+quote, quote, `\\`, `\`).Replace(inner)
+}
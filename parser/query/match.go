@@ -0,0 +1,190 @@
+package query
+
+import (
+	"github.com/mtrense/parsertk/parser"
+)
+
+const (
+	descendant = ' '
+	child      = '>'
+)
+
+// test is a predicate or pseudo-class of a compound selector.
+type test func(node parser.INode, caps *captures) bool
+
+type compound struct {
+	nodeType string
+	any      bool
+	tests    []test
+	capture  string
+}
+
+// step is a compound selector with the combinator relating it to the step
+// before it, or to the scope of a relative selector for the first step.
+type step struct {
+	combinator byte
+	compound   compound
+}
+
+type selector struct {
+	steps []step
+}
+
+type capture struct {
+	name string
+	node parser.INode
+}
+
+// captures collects captures while matching. On failure it is truncated back
+// to the state before the attempt.
+type captures []capture
+
+func (s captures) toMap() map[string]parser.INode {
+	result := make(map[string]parser.INode, len(s))
+	for _, c := range s {
+		result[c.name] = c.node
+	}
+	return result
+}
+
+func matchList(selectors []selector, node parser.INode, caps *captures) bool {
+	for _, sel := range selectors {
+		mark := len(*caps)
+		if sel.match(node, len(sel.steps)-1, nil, caps) {
+			return true
+		}
+		*caps = (*caps)[:mark]
+	}
+	return false
+}
+
+// match reports whether node matches step i of the selector and the steps
+// before it match its ancestors. A non-nil scope bounds the ancestors
+// considered and must be related to the node matching the first step.
+func (s selector) match(node parser.INode, i int, scope parser.INode, caps *captures) bool {
+	mark := len(*caps)
+	if !s.steps[i].compound.match(node, caps) {
+		return false
+	}
+	if i == 0 {
+		if scope == nil || related(node, scope, s.steps[0].combinator) {
+			return true
+		}
+		*caps = (*caps)[:mark]
+		return false
+	}
+	switch s.steps[i].combinator {
+	case child:
+		if parent := node.Parent(); parent != nil && parent != scope && s.match(parent, i-1, scope, caps) {
+			return true
+		}
+	default:
+		for ancestor := node.Parent(); ancestor != nil && ancestor != scope; ancestor = ancestor.Parent() {
+			if s.match(ancestor, i-1, scope, caps) {
+				return true
+			}
+		}
+	}
+	*caps = (*caps)[:mark]
+	return false
+}
+
+// related reports whether node is a child or, for the descendant combinator, a
+// descendant of scope.
+func related(node, scope parser.INode, combinator byte) bool {
+	if combinator == child {
+		return node.Parent() == scope
+	}
+	for ancestor := node.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+		if ancestor == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (s compound) match(node parser.INode, caps *captures) bool {
+	if !s.any && node.NodeType() != s.nodeType {
+		return false
+	}
+	mark := len(*caps)
+	for _, t := range s.tests {
+		if !t(node, caps) {
+			*caps = (*caps)[:mark]
+			return false
+		}
+	}
+	if s.capture != "" {
+		*caps = append(*caps, capture{name: s.capture, node: node})
+	}
+	return true
+}
+
+// has returns a test matching nodes with a descendant matching one of the
+// relative selectors.
+func has(selectors []selector) test {
+	return func(node parser.INode, caps *captures) bool {
+		found := false
+		for _, c := range node.Children() {
+			parser.Walk(c, func(candidate parser.INode, path []parser.INode) parser.WalkAction {
+				for _, sel := range selectors {
+					if sel.match(candidate, len(sel.steps)-1, node, caps) {
+						found = true
+						return parser.Stop
+					}
+				}
+				return parser.Continue
+			}, nil)
+			if found {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func not(selectors []selector) test {
+	return func(node parser.INode, caps *captures) bool {
+		var discarded captures
+		return !matchList(selectors, node, &discarded)
+	}
+}
+
+func siblings(node parser.INode) ([]parser.INode, int) {
+	parent := node.Parent()
+	if parent == nil {
+		return nil, -1
+	}
+	children := parent.Children()
+	for i, c := range children {
+		if c == node {
+			return children, i
+		}
+	}
+	return children, -1
+}
+
+func nthChild(n int) test {
+	return func(node parser.INode, caps *captures) bool {
+		_, index := siblings(node)
+		return index >= 0 && index == n-1
+	}
+}
+
+func lastChild(node parser.INode, caps *captures) bool {
+	children, index := siblings(node)
+	return index >= 0 && index == len(children)-1
+}
+
+func onlyChild(node parser.INode, caps *captures) bool {
+	children, index := siblings(node)
+	return index >= 0 && len(children) == 1
+}
+
+func isRoot(node parser.INode, caps *captures) bool {
+	return node.IsRoot()
+}
+
+func isLeaf(node parser.INode, caps *captures) bool {
+	return node.IsLeaf()
+}
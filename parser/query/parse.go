@@ -0,0 +1,285 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mtrense/parsertk/lexer"
+	"github.com/mtrense/parsertk/parser"
+)
+
+type queryParser struct {
+	tokens []lexer.Token
+	pos    int
+}
+
+func (s *queryParser) peek() lexer.Token {
+	return s.tokens[s.pos]
+}
+
+func (s *queryParser) next() lexer.Token {
+	tok := s.tokens[s.pos]
+	if tok.Typ != tokenEOF {
+		s.pos++
+	}
+	return tok
+}
+
+// at reports whether the next token has type typ and, unless empty, value.
+func (s *queryParser) at(typ lexer.TokenType, value string) bool {
+	tok := s.peek()
+	return tok.Typ == typ && (value == "" || tok.Value == value)
+}
+
+func (s *queryParser) accept(typ lexer.TokenType, value string) bool {
+	if s.at(typ, value) {
+		s.next()
+		return true
+	}
+	return false
+}
+
+func (s *queryParser) expect(typ lexer.TokenType, value string) (lexer.Token, error) {
+	if !s.at(typ, value) {
+		return lexer.Token{}, s.unexpected()
+	}
+	return s.next(), nil
+}
+
+func (s *queryParser) skipWhitespace() bool {
+	return s.accept(tokenWhitespace, "")
+}
+
+func (s *queryParser) unexpected() error {
+	tok := s.peek()
+	if tok.Typ == tokenEOF {
+		return fmt.Errorf("query: unexpected end of query")
+	}
+	return fmt.Errorf("query: unexpected %q at offset %d", tok.Value, tok.Offset)
+}
+
+func (s *queryParser) selectorList() ([]selector, error) {
+	return s.list(func() (selector, error) {
+		return s.selector(descendant)
+	})
+}
+
+// relativeSelectorList parses the argument of :has, where every selector may
+// start with a > combinator.
+func (s *queryParser) relativeSelectorList() ([]selector, error) {
+	return s.list(func() (selector, error) {
+		combinator := byte(descendant)
+		if s.accept(tokenPunct, ">") {
+			combinator = child
+			s.skipWhitespace()
+		}
+		return s.selector(combinator)
+	})
+}
+
+func (s *queryParser) list(item func() (selector, error)) ([]selector, error) {
+	var selectors []selector
+	for {
+		s.skipWhitespace()
+		sel, err := item()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		s.skipWhitespace()
+		if !s.accept(tokenPunct, ",") {
+			return selectors, nil
+		}
+	}
+}
+
+func (s *queryParser) selector(first byte) (selector, error) {
+	var sel selector
+	combinator := first
+	for {
+		c, err := s.compound()
+		if err != nil {
+			return selector{}, err
+		}
+		sel.steps = append(sel.steps, step{combinator: combinator, compound: c})
+		whitespace := s.skipWhitespace()
+		switch {
+		case s.accept(tokenPunct, ">"):
+			combinator = child
+			s.skipWhitespace()
+		case whitespace && s.startsCompound():
+			combinator = descendant
+		default:
+			if whitespace {
+				s.pos--
+			}
+			return sel, nil
+		}
+	}
+}
+
+func (s *queryParser) startsCompound() bool {
+	tok := s.peek()
+	switch tok.Typ {
+	case tokenIdent, tokenString:
+		return true
+	case tokenPunct:
+		return strings.Contains("*[:@", tok.Value)
+	}
+	return false
+}
+
+func (s *queryParser) compound() (compound, error) {
+	var c compound
+	switch tok := s.peek(); {
+	case tok.Typ == tokenIdent:
+		c.nodeType = s.next().Value
+	case tok.Typ == tokenString:
+		c.nodeType = unquote(s.next().Value)
+	case s.accept(tokenPunct, "*"):
+		c.any = true
+	case s.startsCompound():
+		c.any = true
+	default:
+		return compound{}, s.unexpected()
+	}
+	for {
+		var (
+			t   test
+			err error
+		)
+		switch {
+		case s.accept(tokenPunct, "["):
+			t, err = s.predicate()
+		case s.accept(tokenPunct, ":"):
+			t, err = s.pseudoClass()
+		case s.accept(tokenPunct, "@"):
+			var name lexer.Token
+			name, err = s.expect(tokenIdent, "")
+			c.capture = name.Value
+		default:
+			return c, nil
+		}
+		if err != nil {
+			return compound{}, err
+		}
+		if t != nil {
+			c.tests = append(c.tests, t)
+		}
+	}
+}
+
+func (s *queryParser) predicate() (test, error) {
+	s.skipWhitespace()
+	attribute, err := s.expect(tokenIdent, "")
+	if err != nil {
+		return nil, err
+	}
+	var get func(parser.INode) string
+	switch attribute.Value {
+	case "value":
+		get = parser.INode.String
+	case "type":
+		get = parser.INode.NodeType
+	default:
+		return nil, fmt.Errorf("query: unknown attribute %q at offset %d", attribute.Value, attribute.Offset)
+	}
+	s.skipWhitespace()
+	operator, err := s.expect(tokenOperator, "")
+	if err != nil {
+		return nil, err
+	}
+	s.skipWhitespace()
+	operand, err := s.expect(tokenString, "")
+	if err != nil {
+		return nil, err
+	}
+	value := unquote(operand.Value)
+	s.skipWhitespace()
+	if _, err := s.expect(tokenPunct, "]"); err != nil {
+		return nil, err
+	}
+	var compare func(string) bool
+	switch operator.Value {
+	case "=":
+		compare = func(v string) bool { return v == value }
+	case "!=":
+		compare = func(v string) bool { return v != value }
+	case "^=":
+		compare = func(v string) bool { return strings.HasPrefix(v, value) }
+	case "$=":
+		compare = func(v string) bool { return strings.HasSuffix(v, value) }
+	case "*=":
+		compare = func(v string) bool { return strings.Contains(v, value) }
+	case "~=":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("query: invalid regular expression at offset %d: %w", operand.Offset, err)
+		}
+		compare = re.MatchString
+	}
+	return func(node parser.INode, caps *captures) bool {
+		return compare(get(node))
+	}, nil
+}
+
+func (s *queryParser) pseudoClass() (test, error) {
+	name, err := s.expect(tokenIdent, "")
+	if err != nil {
+		return nil, err
+	}
+	switch name.Value {
+	case "first-child":
+		return nthChild(1), nil
+	case "last-child":
+		return lastChild, nil
+	case "only-child":
+		return onlyChild, nil
+	case "root":
+		return isRoot, nil
+	case "leaf", "empty":
+		return isLeaf, nil
+	case "nth-child":
+		return s.argument(func() (test, error) {
+			tok, err := s.expect(tokenIdent, "")
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.Atoi(tok.Value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("query: invalid index %q at offset %d", tok.Value, tok.Offset)
+			}
+			return nthChild(n), nil
+		})
+	case "has":
+		return s.argument(func() (test, error) {
+			selectors, err := s.relativeSelectorList()
+			return has(selectors), err
+		})
+	case "not":
+		return s.argument(func() (test, error) {
+			selectors, err := s.selectorList()
+			return not(selectors), err
+		})
+	}
+	return nil, fmt.Errorf("query: unknown pseudo-class %q at offset %d", name.Value, name.Offset)
+}
+
+// argument parses a parenthesized pseudo-class argument using parse.
+func (s *queryParser) argument(parse func() (test, error)) (test, error) {
+	if _, err := s.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+	s.skipWhitespace()
+	t, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	s.skipWhitespace()
+	if _, err := s.expect(tokenPunct, ")"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
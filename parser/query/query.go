@@ -0,0 +1,110 @@
+// Package query finds nodes in INode trees using CSS-like selectors. A selector
+// is a sequence of compound selectors joined by combinators:
+//
+//	call                   nodes whose NodeType() is call
+//	*                      any node
+//	block > stmt           stmt nodes that are children of a block node
+//	func ident             ident nodes anywhere below a func node
+//	ident, literal         nodes matching either selector
+//
+// A compound selector may be refined by predicates on the value (String()) or
+// type (NodeType()) of a node, pseudo-classes and a capture:
+//
+//	[value="print"]        value equals, also != ^= (prefix) $= (suffix)
+//	                       *= (contains) and ~= (regular expression)
+//	:first-child :last-child :only-child :nth-child(2) :root :leaf
+//	:has(> ident)          a child, or without > a descendant, matches
+//	:not(literal)          the node does not match the selector list
+//	@name                  the node is captured as name
+//
+// For example call:has(> ident:first-child[value="print"]@fn) finds every call
+// whose first child is an identifier named print, capturing the identifier as
+// fn.
+package query
+
+import (
+	"github.com/mtrense/parsertk/parser"
+)
+
+// Query is a compiled selector list. It can be executed on any number of trees.
+type Query struct {
+	source    string
+	selectors []selector
+}
+
+// Match is a node matched by a Query with the nodes captured on the way.
+type Match struct {
+	Node     parser.INode
+	Captures map[string]parser.INode
+}
+
+// Compile parses source into a Query.
+func Compile(source string) (*Query, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	selectors, err := p.selectorList()
+	if err != nil {
+		return nil, err
+	}
+	if !p.at(tokenEOF, "") {
+		return nil, p.unexpected()
+	}
+	return &Query{
+		source:    source,
+		selectors: selectors,
+	}, nil
+}
+
+// MustCompile is like Compile but panics if source is invalid.
+func MustCompile(source string) *Query {
+	q, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (s *Query) String() string {
+	return s.source
+}
+
+// Matches reports whether node matches the query, returning the captures.
+func (s *Query) Matches(node parser.INode) (map[string]parser.INode, bool) {
+	var caps captures
+	if !matchList(s.selectors, node, &caps) {
+		return nil, false
+	}
+	return caps.toMap(), true
+}
+
+// All returns all nodes of the tree below root, including root, that match the
+// query, in pre-order.
+func (s *Query) All(root parser.INode) []Match {
+	var matches []Match
+	parser.Walk(root, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		if caps, ok := s.Matches(node); ok {
+			matches = append(matches, Match{Node: node, Captures: caps})
+		}
+		return parser.Continue
+	}, nil)
+	return matches
+}
+
+// First returns the first node in pre-order that matches the query.
+func (s *Query) First(root parser.INode) (Match, bool) {
+	var (
+		match Match
+		found bool
+	)
+	parser.Walk(root, func(node parser.INode, path []parser.INode) parser.WalkAction {
+		if caps, ok := s.Matches(node); ok {
+			match, found = Match{Node: node, Captures: caps}, true
+			return parser.Stop
+		}
+		return parser.Continue
+	}, nil)
+	return match, found
+}
@@ -0,0 +1,120 @@
+package query
+
+import (
+	"github.com/mtrense/parsertk/parser/internal/testutil"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var n = testutil.Tree
+
+func values(matches []Match) []string {
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.Node.NodeType() + ":" + m.Node.String()
+	}
+	return result
+}
+
+// program is the tree of
+//
+//	print("hi"); foo(print(1)); { x = print }
+var program = n("program", "",
+	n("call", "c1", n("ident", "print"), n("string", "hi")),
+	n("call", "c2", n("ident", "foo"), n("call", "c3", n("ident", "print"), n("number", "1"))),
+	n("block", "b1", n("assign", "a1", n("ident", "x"), n("ident", "print"))),
+)
+
+func all(source string) []string {
+	return values(MustCompile(source).All(program))
+}
+
+var _ = Describe("Query", func() {
+	It("selects by node type", func() {
+		Expect(all("call")).To(Equal([]string{"call:c1", "call:c2", "call:c3"}))
+		Expect(all(`"number"`)).To(Equal([]string{"number:1"}))
+	})
+	It("selects any node", func() {
+		Expect(all("*")).To(HaveLen(13))
+	})
+	It("selects children", func() {
+		Expect(all("program > call")).To(Equal([]string{"call:c1", "call:c2"}))
+		Expect(all("call>call>ident")).To(Equal([]string{"ident:print"}))
+	})
+	It("selects descendants", func() {
+		Expect(all("program ident")).To(HaveLen(5))
+		Expect(all("block ident")).To(Equal([]string{"ident:x", "ident:print"}))
+		Expect(all("program  call  number")).To(Equal([]string{"number:1"}))
+	})
+	It("combines child and descendant combinators", func() {
+		Expect(all("program > call ident")).To(Equal([]string{"ident:print", "ident:foo", "ident:print"}))
+	})
+	It("selects by value", func() {
+		Expect(all(`ident[value="print"]`)).To(HaveLen(3))
+		Expect(all(`ident[value != "print"]`)).To(Equal([]string{"ident:foo", "ident:x"}))
+		Expect(all(`*[value^="c"]`)).To(Equal([]string{"call:c1", "call:c2", "call:c3"}))
+		Expect(all(`*[value$="1"]`)).To(Equal([]string{"call:c1", "number:1", "block:b1", "assign:a1"}))
+		Expect(all(`[value*="oo"]`)).To(Equal([]string{"ident:foo"}))
+		Expect(all(`[value~="^\d+$"]`)).To(Equal([]string{"number:1"}))
+		Expect(all(`[type='assign']`)).To(Equal([]string{"assign:a1"}))
+	})
+	It("supports structural pseudo-classes", func() {
+		Expect(all("ident:first-child")).To(HaveLen(4))
+		Expect(all("ident:last-child")).To(Equal([]string{"ident:print"}))
+		Expect(all("call:nth-child(2)")).To(Equal([]string{"call:c2", "call:c3"}))
+		Expect(all(":only-child")).To(Equal([]string{"assign:a1"}))
+		Expect(all(":root")).To(Equal([]string{"program:"}))
+		Expect(all("call > :leaf:last-child")).To(Equal([]string{"string:hi", "number:1"}))
+	})
+	It("supports :has", func() {
+		Expect(all(`call:has(> ident:first-child[value="print"])`)).To(Equal([]string{"call:c1", "call:c3"}))
+		Expect(all(`call:has(number)`)).To(Equal([]string{"call:c2", "call:c3"}))
+		Expect(all(`call:has(> number)`)).To(Equal([]string{"call:c3"}))
+		Expect(all(`*:has(> call > number)`)).To(Equal([]string{"call:c2"}))
+		Expect(all(`:has(> assign, > string)`)).To(Equal([]string{"call:c1", "block:b1"}))
+	})
+	It("does not look above the scope of :has", func() {
+		Expect(all(`call:has(program ident)`)).To(BeEmpty())
+	})
+	It("supports :not", func() {
+		Expect(all(`ident:not([value="print"], block ident)`)).To(Equal([]string{"ident:foo"}))
+	})
+	It("supports selector lists", func() {
+		Expect(all("number, string")).To(Equal([]string{"string:hi", "number:1"}))
+	})
+	It("captures nodes", func() {
+		matches := MustCompile(`program > call@call:has(> ident:first-child@fn) > call > number@arg`).All(program)
+		Expect(matches).To(HaveLen(1))
+		captures := matches[0].Captures
+		Expect(captures["call"].String()).To(Equal("c2"))
+		Expect(captures["fn"].String()).To(Equal("foo"))
+		Expect(captures["arg"]).To(BeIdenticalTo(matches[0].Node))
+	})
+	It("discards captures of failed attempts", func() {
+		matches := MustCompile(`block@outer ident@id, ident@other`).All(program)
+		Expect(matches[0].Captures).To(HaveKey("other"))
+		Expect(matches[0].Captures).ToNot(HaveKey("id"))
+		Expect(matches[3].Captures).To(HaveKey("outer"))
+	})
+	It("finds the first match", func() {
+		match, ok := MustCompile(`ident[value="print"]`).First(program)
+		Expect(ok).To(BeTrue())
+		Expect(match.Node.Parent().String()).To(Equal("c1"))
+		_, ok = MustCompile(`missing`).First(program)
+		Expect(ok).To(BeFalse())
+	})
+	It("tests single nodes", func() {
+		q := MustCompile(`ident:last-child`)
+		_, ok := q.Matches(program.Children()[2].Children()[0].Children()[1])
+		Expect(ok).To(BeTrue())
+		_, ok = q.Matches(program)
+		Expect(ok).To(BeFalse())
+	})
+	It("reports syntax errors", func() {
+		for _, source := range []string{"", "call >", "call[", `call[value="x"`, "call:foo", "call:nth-child(0)", `[size="1"]`, `[value~="("]`, "a ! b", "a,", "call:has(", "a b)"} {
+			_, err := Compile(source)
+			Expect(err).To(HaveOccurred(), source)
+		}
+		Expect(func() { MustCompile("#") }).To(Panic())
+	})
+})
@@ -0,0 +1,120 @@
+package parser
+
+// RewriteHandler returns the nodes replacing node. Returning node alone keeps
+// it, returning no nodes deletes it and returning several nodes splices them in
+// its place.
+type RewriteHandler func(node INode) []INode
+
+// Keep is a RewriteHandler leaving the node in place.
+func Keep(node INode) []INode {
+	return []INode{node}
+}
+
+// Delete is a RewriteHandler removing the node.
+func Delete(node INode) []INode {
+	return nil
+}
+
+// Rewriter replaces nodes by the result of the RewriteHandler registered for
+// their type, looked up like handlers of a Dispatcher.
+type Rewriter struct {
+	handlers HandlerTable
+}
+
+func NewRewriter() *Rewriter {
+	return &Rewriter{
+		handlers: NewHandlerTable(),
+	}
+}
+
+// On registers handler for nodes whose NodeType() is nodeType.
+func (s *Rewriter) On(nodeType string, handler RewriteHandler) *Rewriter {
+	s.handlers.On(nodeType, handler)
+	return s
+}
+
+// OnType registers handler for nodes of the same Go type as example.
+func (s *Rewriter) OnType(example INode, handler RewriteHandler) *Rewriter {
+	s.handlers.OnType(example, handler)
+	return s
+}
+
+// Otherwise registers handler for nodes no other handler is registered for.
+func (s *Rewriter) Otherwise(handler RewriteHandler) *Rewriter {
+	s.handlers.Otherwise(handler)
+	return s
+}
+
+// Rewrite rewrites the tree below node bottom-up, so handlers see the already
+// rewritten children of a node. Nodes returned by a handler are not rewritten
+// again. Rewrite returns the nodes replacing node; if node has a parent, they
+// are spliced into its children in place of node. Nodes are moved with
+// AppendChild and Detach, so returned nodes are detached from their previous
+// parents and removed nodes become roots. Rewrite stops at the first node that
+// cannot be moved, e.g. because it would become its own ancestor.
+func (s *Rewriter) Rewrite(node INode) ([]INode, error) {
+	parent := node.Parent()
+	// pending collects the rewritten children of every node on the path.
+	var (
+		pending  [][]INode
+		replaced []INode
+		err      error
+	)
+	Walk(node, func(INode, []INode) WalkAction {
+		pending = append(pending, nil)
+		return Continue
+	}, func(current INode, path []INode) WalkAction {
+		children := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if !current.IsLeaf() || len(children) > 0 {
+			if err = adopt(current, children); err != nil {
+				return Stop
+			}
+		}
+		replacements := []INode{current}
+		if handler, ok := s.handlers.Lookup(current).(RewriteHandler); ok {
+			replacements = handler(current)
+		}
+		if len(pending) > 0 {
+			pending[len(pending)-1] = append(pending[len(pending)-1], replacements...)
+		} else {
+			replaced = replacements
+		}
+		return Continue
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parent != nil {
+		var siblings []INode
+		for _, sibling := range parent.Children() {
+			if same(sibling, node) {
+				siblings = append(siblings, replaced...)
+			} else {
+				siblings = append(siblings, sibling)
+			}
+		}
+		if err := adopt(parent, siblings); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, replacement := range replaced {
+			Detach(replacement)
+		}
+	}
+	return replaced, nil
+}
+
+// adopt replaces the children of parent by children, restoring the previous
+// children if that fails.
+func adopt(parent INode, children []INode) error {
+	previous := append([]INode(nil), parent.Children()...)
+	for _, child := range previous {
+		Detach(child)
+	}
+	if err := AppendChild(parent, children...); err != nil {
+		AppendChild(parent, previous...)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// shape renders a tree as S-expression of node types, checking parent links on
+// the way.
+func shape(node INode) string {
+	if node.IsLeaf() {
+		return node.NodeType()
+	}
+	parts := []string{node.NodeType()}
+	for _, child := range node.Children() {
+		Expect(child.Parent()).To(BeIdenticalTo(node))
+		parts = append(parts, shape(child))
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+var _ = Describe("Rewriter", func() {
+	It("replaces nodes", func() {
+		root := tree("a", tree("b", tree("c")), tree("c"))
+		result, err := NewRewriter().On("c", func(node INode) []INode {
+			return []INode{tree("x")}
+		}).Rewrite(root)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal([]INode{root}))
+		Expect(shape(root)).To(Equal("(a (b x) x)"))
+	})
+	It("deletes nodes", func() {
+		c1, c2 := tree("c"), tree("c")
+		root := tree("a", tree("b", c1), c2, tree("d"))
+		NewRewriter().On("c", Delete).Rewrite(root)
+		Expect(shape(root)).To(Equal("(a b d)"))
+		Expect(root.Children()[0].IsLeaf()).To(BeTrue())
+		Expect(c1.IsRoot()).To(BeTrue())
+		Expect(c2.IsRoot()).To(BeTrue())
+	})
+	It("splices in several nodes", func() {
+		root := tree("a", tree("group", tree("b"), tree("c")), tree("d"))
+		NewRewriter().On("group", func(node INode) []INode {
+			return node.Children()
+		}).Rewrite(root)
+		Expect(shape(root)).To(Equal("(a b c d)"))
+	})
+	It("rewrites bottom-up", func() {
+		root := tree("add", tree("add", tree("1"), tree("2")), tree("3"))
+		var trace []string
+		NewRewriter().On("add", func(node INode) []INode {
+			children := node.Children()
+			name := children[0].NodeType() + "+" + children[1].NodeType()
+			trace = append(trace, name)
+			return []INode{tree(name)}
+		}).Rewrite(root)
+		Expect(trace).To(Equal([]string{"1+2", "1+2+3"}))
+	})
+	It("replaces the root", func() {
+		result, err := NewRewriter().On("a", func(node INode) []INode {
+			return []INode{tree("r", node.Children()...)}
+		}).Rewrite(tree("a", tree("b")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].IsRoot()).To(BeTrue())
+		Expect(shape(result[0])).To(Equal("(r b)"))
+	})
+	It("splices the rewritten subtree into its parent", func() {
+		root := tree("a", tree("x"), tree("b", tree("c")), tree("y"))
+		NewRewriter().On("b", func(node INode) []INode {
+			return []INode{tree("b1"), tree("b2")}
+		}).Rewrite(root.Children()[1])
+		Expect(shape(root)).To(Equal("(a x b1 b2 y)"))
+	})
+	It("dispatches by Go type", func() {
+		root := &Node{}
+		Expect(AppendChild(root, tree("a"))).To(Succeed())
+		NewRewriter().OnType(&namedNode{}, Delete).Otherwise(Keep).Rewrite(root)
+		Expect(root.IsLeaf()).To(BeTrue())
+	})
+	It("splices into the parent of an embedded Node", func() {
+		root := tree("a", tree("b"))
+		b := root.Children()[0].(*namedNode)
+		_, err := NewRewriter().Otherwise(func(INode) []INode {
+			return []INode{tree("c")}
+		}).Rewrite(&b.Node)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shape(root)).To(Equal("(a c)"))
+	})
+	It("detaches returned nodes from their previous parents", func() {
+		other := tree("other", tree("x"))
+		x := other.Children()[0]
+		root := tree("a", tree("b"))
+		_, err := NewRewriter().On("b", func(node INode) []INode {
+			return []INode{x}
+		}).Rewrite(root)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shape(root)).To(Equal("(a x)"))
+		Expect(other.IsLeaf()).To(BeTrue())
+	})
+	It("rejects cycles", func() {
+		root := tree("a", tree("b"))
+		_, err := NewRewriter().On("b", func(node INode) []INode {
+			return []INode{root}
+		}).Rewrite(root)
+		Expect(err).To(MatchError(ErrCycle))
+		Expect(shape(root)).To(Equal("(a b)"))
+	})
+})
@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// TreePrinter writes trees in the format of DumpTree, one node per line, with
+// optional box-drawing connectors, colors, spans and source excerpts.
+type TreePrinter struct {
+	connectors   bool
+	colors       map[string]*color.Color
+	spans        bool
+	source       []rune
+	excerptWidth int
+	maxDepth     int
+	maxChildren  int
+}
+
+func NewTreePrinter() *TreePrinter {
+	return &TreePrinter{
+		colors: make(map[string]*color.Color),
+	}
+}
+
+// Connectors draws the tree structure with box-drawing characters instead of
+// indenting with spaces.
+func (s *TreePrinter) Connectors() *TreePrinter {
+	s.connectors = true
+	return s
+}
+
+// Define prints nodes of nodeType in color c.
+func (s *TreePrinter) Define(nodeType string, c *color.Color) *TreePrinter {
+	s.colors[nodeType] = c
+	return s
+}
+
+// Spans prints the span of every node.
+func (s *TreePrinter) Spans() *TreePrinter {
+	s.spans = true
+	return s
+}
+
+// Excerpts prints the part of source covered by every node, shortened to
+// width runes.
+func (s *TreePrinter) Excerpts(source string, width int) *TreePrinter {
+	s.source = []rune(source)
+	s.excerptWidth = width
+	return s
+}
+
+// MaxDepth omits the children of nodes depth levels below the root. Zero means
+// no limit.
+func (s *TreePrinter) MaxDepth(depth int) *TreePrinter {
+	s.maxDepth = depth
+	return s
+}
+
+// MaxChildren prints at most n children of each node and a summary of the
+// rest. Zero means no limit.
+func (s *TreePrinter) MaxChildren(n int) *TreePrinter {
+	s.maxChildren = n
+	return s
+}
+
+// Print writes the tree below root to w.
+func (s *TreePrinter) Print(w io.Writer, root INode) error {
+	out := bufio.NewWriter(w)
+	s.print(out, root, 0, "", "")
+	return out.Flush()
+}
+
+// print writes node after prefix and its children after childPrefix.
+func (s *TreePrinter) print(out *bufio.Writer, node INode, depth int, prefix, childPrefix string) {
+	out.WriteString(prefix + s.label(node) + "\n")
+	children := node.Children()
+	if len(children) == 0 {
+		return
+	}
+	if s.maxDepth > 0 && depth >= s.maxDepth {
+		s.elision(out, childPrefix, len(children), "child", "children")
+		return
+	}
+	shown := children
+	if s.maxChildren > 0 && len(children) > s.maxChildren {
+		shown = children[:s.maxChildren]
+	}
+	for i, child := range shown {
+		last := i == len(children)-1
+		s.print(out, child, depth+1, childPrefix+s.connector(last), childPrefix+s.continuation(last))
+	}
+	if len(shown) < len(children) {
+		s.elision(out, childPrefix, len(children)-len(shown), "more child", "more children")
+	}
+}
+
+func (s *TreePrinter) elision(out *bufio.Writer, prefix string, n int, singular, plural string) {
+	noun := plural
+	if n == 1 {
+		noun = singular
+	}
+	fmt.Fprintf(out, "%s%s… %d %s\n", prefix, s.connector(true), n, noun)
+}
+
+func (s *TreePrinter) connector(last bool) string {
+	switch {
+	case !s.connectors:
+		return "  "
+	case last:
+		return "└── "
+	}
+	return "├── "
+}
+
+func (s *TreePrinter) continuation(last bool) string {
+	switch {
+	case !s.connectors:
+		return "  "
+	case last:
+		return "    "
+	}
+	return "│   "
+}
+
+func (s *TreePrinter) label(node INode) string {
+	label := fmt.Sprintf("[%s] %v", node.NodeType(), node.String())
+	if c, ok := s.colors[node.NodeType()]; ok {
+		label = c.Sprint(label)
+	}
+	bracket := node.Bracket()
+	if s.spans {
+		label += fmt.Sprintf(" %d..%d", bracket.StartOffset, bracket.StartOffset+bracket.Length)
+	}
+	if s.source != nil {
+		label += " " + s.excerpt(bracket)
+	}
+	return label
+}
+
+func (s *TreePrinter) excerpt(bracket Bracket) string {
+	start := clamp(bracket.StartOffset, 0, len(s.source))
+	end := clamp(bracket.StartOffset+bracket.Length, start, len(s.source))
+	text := s.source[start:end]
+	if s.excerptWidth > 0 && len(text) > s.excerptWidth {
+		return fmt.Sprintf("%q…", string(text[:s.excerptWidth]))
+	}
+	return fmt.Sprintf("%q", string(text))
+}
+
+func clamp(n, min, max int) int {
+	switch {
+	case n < min:
+		return min
+	case n > max:
+		return max
+	}
+	return n
+}
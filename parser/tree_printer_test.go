@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"bytes"
+
+	"github.com/fatih/color"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// spanned builds a namedNode covering bracket with the given children.
+func spanned(name string, bracket Bracket, children ...INode) INode {
+	node := &namedNode{Node: NewNodeAt(nil, bracket), name: name}
+	Expect(AppendChild(node, children...)).To(Succeed())
+	return node
+}
+
+var _ = Describe("TreePrinter", func() {
+	var root INode
+
+	BeforeEach(func() {
+		root = tree("a", tree("b", tree("c"), tree("d")), tree("e", tree("f")))
+	})
+
+	print := func(printer *TreePrinter, node INode) string {
+		var out bytes.Buffer
+		Expect(printer.Print(&out, node)).To(Succeed())
+		return out.String()
+	}
+
+	It("indents with spaces like DumpTree", func() {
+		Expect(print(NewTreePrinter(), root)).To(Equal("[a] a\n  [b] b\n    [c] c\n    [d] d\n  [e] e\n    [f] f\n"))
+	})
+	It("draws box connectors", func() {
+		Expect(print(NewTreePrinter().Connectors(), root)).To(Equal(
+			"[a] a\n" +
+				"├── [b] b\n" +
+				"│   ├── [c] c\n" +
+				"│   └── [d] d\n" +
+				"└── [e] e\n" +
+				"    └── [f] f\n"))
+	})
+	It("limits the depth", func() {
+		Expect(print(NewTreePrinter().Connectors().MaxDepth(1), root)).To(Equal(
+			"[a] a\n" +
+				"├── [b] b\n" +
+				"│   └── … 2 children\n" +
+				"└── [e] e\n" +
+				"    └── … 1 child\n"))
+	})
+	It("elides long child lists", func() {
+		wide := tree("r", tree("1"), tree("2"), tree("3"), tree("4"))
+		Expect(print(NewTreePrinter().Connectors().MaxChildren(2), wide)).To(Equal(
+			"[r] r\n" +
+				"├── [1] 1\n" +
+				"├── [2] 2\n" +
+				"└── … 2 more children\n"))
+		Expect(print(NewTreePrinter().MaxChildren(4), wide)).To(Equal("[r] r\n  [1] 1\n  [2] 2\n  [3] 3\n  [4] 4\n"))
+	})
+	It("prints spans and source excerpts", func() {
+		node := spanned("sum", Bracket{0, 12}, spanned("num", Bracket{0, 1}), spanned("call", Bracket{4, 8}))
+		Expect(print(NewTreePrinter().Spans().Excerpts("1 + max(2,3)", 5), node)).To(Equal(
+			"[sum] sum 0..12 \"1 + m\"…\n" +
+				"  [num] num 0..1 \"1\"\n" +
+				"  [call] call 4..12 \"max(2\"…\n"))
+	})
+	It("colors nodes by type", func() {
+		red := color.New(color.FgRed)
+		red.EnableColor()
+		Expect(print(NewTreePrinter().Define("c", red), tree("b", tree("c")))).To(Equal("[b] b\n  \x1b[31m[c] c\x1b[0m\n"))
+	})
+})
@@ -1,8 +1,72 @@
 package parser
 
-type TreeWalker func(n *Node)
+// WalkAction tells Walk how to continue after a callback.
+type WalkAction int
 
-func (s *Node) Walk(walker TreeWalker) {
+const (
+	// Continue descends into the children of the node.
+	Continue WalkAction = iota
+	// SkipChildren leaves out the children of the node. Returned from a
+	// post-order callback it is the same as Continue.
+	SkipChildren
+	// Stop aborts the walk.
+	Stop
+)
 
+// TreeWalker is called for every node visited by Walk. path holds the nodes
+// from the root down to node, which is its last element. The slice is reused
+// during the walk and must be copied to be retained.
+type TreeWalker func(node INode, path []INode) WalkAction
+
+// Walk traverses the tree below root depth first, calling pre before and post
+// after the children of a node are visited. Either callback may be nil. Post is
+// called for every node pre has been called for unless the walk is stopped.
+// Walk reports whether the walk ran to completion. It keeps its own stack, so
+// arbitrarily deep trees can be walked.
+func Walk(root INode, pre, post TreeWalker) bool {
+	type frame struct {
+		node INode
+		next int
+		skip bool
+	}
+	var (
+		stack []frame
+		path  []INode
+	)
+	enter := func(node INode) bool {
+		path = append(path, node)
+		action := Continue
+		if pre != nil {
+			action = pre(node, path)
+		}
+		stack = append(stack, frame{node: node, skip: action == SkipChildren})
+		return action != Stop
+	}
+	if root == nil || !enter(root) {
+		return false
+	}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if children := top.node.Children(); !top.skip && top.next < len(children) {
+			child := children[top.next]
+			top.next++
+			if child != nil && !enter(child) {
+				return false
+			}
+			continue
+		}
+		if post != nil && post(top.node, path) == Stop {
+			return false
+		}
+		stack = stack[:len(stack)-1]
+		path = path[:len(path)-1]
+	}
+	return true
 }
 
+// Walk traverses the tree below s, see Walk. Types embedding Node should call
+// Walk with themselves instead, so the root is passed to the callbacks as the
+// embedding type.
+func (s *Node) Walk(pre, post TreeWalker) bool {
+	return Walk(s, pre, post)
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type namedNode struct {
+	Node
+	name string
+}
+
+func (s *namedNode) NodeType() string {
+	return s.name
+}
+
+func (s *namedNode) String() string {
+	return s.name
+}
+
+// tree builds a namedNode with the given children.
+func tree(name string, children ...INode) INode {
+	node := &namedNode{name: name}
+	Expect(AppendChild(node, children...)).To(Succeed())
+	return node
+}
+
+func names(path []INode) []string {
+	result := make([]string, len(path))
+	for i, node := range path {
+		result[i] = node.NodeType()
+	}
+	return result
+}
+
+var _ = Describe("Walk", func() {
+	var (
+		root  INode
+		trace []string
+	)
+	record := func(prefix string, action WalkAction) TreeWalker {
+		return func(node INode, path []INode) WalkAction {
+			trace = append(trace, prefix+node.NodeType())
+			return action
+		}
+	}
+	BeforeEach(func() {
+		root = tree("a", tree("b", tree("c"), tree("d")), tree("e", tree("f")))
+		trace = nil
+	})
+	It("visits nodes in pre-order", func() {
+		Expect(Walk(root, record("", Continue), nil)).To(BeTrue())
+		Expect(trace).To(Equal([]string{"a", "b", "c", "d", "e", "f"}))
+	})
+	It("visits nodes in post-order", func() {
+		Walk(root, nil, record("", Continue))
+		Expect(trace).To(Equal([]string{"c", "d", "b", "f", "e", "a"}))
+	})
+	It("interleaves pre- and post-order callbacks", func() {
+		Walk(root.Children()[1], record("+", Continue), record("-", Continue))
+		Expect(trace).To(Equal([]string{"+e", "+f", "-f", "-e"}))
+	})
+	It("skips subtrees", func() {
+		Walk(root, func(node INode, path []INode) WalkAction {
+			trace = append(trace, node.NodeType())
+			if node.NodeType() == "b" {
+				return SkipChildren
+			}
+			return Continue
+		}, record("-", Continue))
+		Expect(trace).To(Equal([]string{"a", "b", "-b", "e", "f", "-f", "-e", "-a"}))
+	})
+	It("stops the walk", func() {
+		completed := Walk(root, func(node INode, path []INode) WalkAction {
+			trace = append(trace, node.NodeType())
+			if node.NodeType() == "d" {
+				return Stop
+			}
+			return Continue
+		}, record("-", Continue))
+		Expect(completed).To(BeFalse())
+		Expect(trace).To(Equal([]string{"a", "b", "c", "-c", "d"}))
+	})
+	It("stops the walk from the post-order callback", func() {
+		Expect(Walk(root, nil, record("", Stop))).To(BeFalse())
+		Expect(trace).To(Equal([]string{"c"}))
+	})
+	It("passes the path from the root", func() {
+		var paths [][]string
+		Walk(root, func(node INode, path []INode) WalkAction {
+			paths = append(paths, names(path))
+			return Continue
+		}, nil)
+		Expect(paths).To(Equal([][]string{{"a"}, {"a", "b"}, {"a", "b", "c"}, {"a", "b", "d"}, {"a", "e"}, {"a", "e", "f"}}))
+	})
+	It("walks Nodes", func() {
+		root := &Node{}
+		Expect(AppendChild(root, &Node{}, &Node{})).To(Succeed())
+		count := 0
+		root.Walk(func(node INode, path []INode) WalkAction {
+			count++
+			return Continue
+		}, nil)
+		Expect(count).To(Equal(3))
+	})
+	It("walks very deep trees", func() {
+		const depth = 1000000
+		deepest := &Node{}
+		var node INode = deepest
+		for i := 0; i < depth; i++ {
+			parent := &Node{}
+			Expect(AppendChild(parent, node)).To(Succeed())
+			node = parent
+		}
+		maxDepth := 0
+		Walk(node, func(node INode, path []INode) WalkAction {
+			if len(path) > maxDepth {
+				maxDepth = len(path)
+			}
+			return Continue
+		}, nil)
+		Expect(maxDepth).To(Equal(depth + 1))
+	})
+})
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"reflect"
+)
+
+// HandlerTable looks up the handler registered for a node, first by its Go
+// type, then by its NodeType(), falling back to the one set with Otherwise.
+// Handlers are stored as interface{}, so types dispatching to their own kind
+// of handler, like Dispatcher and Rewriter, assert them on lookup.
+type HandlerTable struct {
+	byGoType   map[reflect.Type]interface{}
+	byNodeType map[string]interface{}
+	fallback   interface{}
+}
+
+func NewHandlerTable() HandlerTable {
+	return HandlerTable{
+		byGoType:   make(map[reflect.Type]interface{}),
+		byNodeType: make(map[string]interface{}),
+	}
+}
+
+// On registers handler for nodes whose NodeType() is nodeType.
+func (s *HandlerTable) On(nodeType string, handler interface{}) {
+	s.byNodeType[nodeType] = handler
+}
+
+// OnType registers handler for nodes of the same Go type as example.
+func (s *HandlerTable) OnType(example INode, handler interface{}) {
+	s.byGoType[reflect.TypeOf(example)] = handler
+}
+
+// Otherwise registers handler for nodes no other handler is registered for.
+func (s *HandlerTable) Otherwise(handler interface{}) {
+	s.fallback = handler
+}
+
+// Lookup returns the handler for node, or nil.
+func (s *HandlerTable) Lookup(node INode) interface{} {
+	if handler, ok := s.byGoType[reflect.TypeOf(node)]; ok {
+		return handler
+	}
+	if handler, ok := s.byNodeType[node.NodeType()]; ok {
+		return handler
+	}
+	return s.fallback
+}
+
+// Dispatcher calls the TreeWalker registered for the type of each node it
+// visits. Handlers registered for a Go type take precedence over those
+// registered for a NodeType(). Nodes without handler are passed to the
+// fallback set with Otherwise, if any.
+type Dispatcher struct {
+	handlers HandlerTable
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		handlers: NewHandlerTable(),
+	}
+}
+
+// On registers handler for nodes whose NodeType() is nodeType.
+func (s *Dispatcher) On(nodeType string, handler TreeWalker) *Dispatcher {
+	s.handlers.On(nodeType, handler)
+	return s
+}
+
+// OnType registers handler for nodes of the same Go type as example.
+func (s *Dispatcher) OnType(example INode, handler TreeWalker) *Dispatcher {
+	s.handlers.OnType(example, handler)
+	return s
+}
+
+// Otherwise registers handler for nodes no other handler is registered for.
+func (s *Dispatcher) Otherwise(handler TreeWalker) *Dispatcher {
+	s.handlers.Otherwise(handler)
+	return s
+}
+
+// Visit implements TreeWalker, so a Dispatcher can be passed to Walk as
+// pre-order or post-order callback.
+func (s *Dispatcher) Visit(node INode, path []INode) WalkAction {
+	if handler, ok := s.handlers.Lookup(node).(TreeWalker); ok {
+		return handler(node, path)
+	}
+	return Continue
+}
+
+// Walk walks the tree below root, calling the handlers in pre-order.
+func (s *Dispatcher) Walk(root INode) bool {
+	return Walk(root, s.Visit, nil)
+}
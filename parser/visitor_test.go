@@ -0,0 +1,65 @@
+package parser
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dispatcher", func() {
+	It("dispatches by NodeType and Go type", func() {
+		var trace []string
+		root := &Node{}
+		Expect(AppendChild(root, tree("call", tree("ident")), tree("ident"))).To(Succeed())
+		NewDispatcher().
+			On("call", func(node INode, path []INode) WalkAction {
+				trace = append(trace, "call")
+				return SkipChildren
+			}).
+			On("ident", func(node INode, path []INode) WalkAction {
+				trace = append(trace, "ident")
+				return Continue
+			}).
+			OnType(&Node{}, func(node INode, path []INode) WalkAction {
+				trace = append(trace, "node")
+				return Continue
+			}).
+			Walk(root)
+		Expect(trace).To(Equal([]string{"node", "call", "ident"}))
+	})
+	It("prefers Go type handlers", func() {
+		var trace []string
+		NewDispatcher().
+			On("a", func(node INode, path []INode) WalkAction {
+				trace = append(trace, "by node type")
+				return Continue
+			}).
+			OnType(&namedNode{}, func(node INode, path []INode) WalkAction {
+				trace = append(trace, "by go type")
+				return Continue
+			}).
+			Walk(tree("a"))
+		Expect(trace).To(Equal([]string{"by go type"}))
+	})
+	It("falls back to Otherwise", func() {
+		var trace []string
+		NewDispatcher().
+			On("b", func(node INode, path []INode) WalkAction {
+				return Continue
+			}).
+			Otherwise(func(node INode, path []INode) WalkAction {
+				trace = append(trace, node.NodeType())
+				return Continue
+			}).
+			Walk(tree("a", tree("b"), tree("c")))
+		Expect(trace).To(Equal([]string{"a", "c"}))
+	})
+	It("serves as post-order callback", func() {
+		var trace []string
+		leave := NewDispatcher().Otherwise(func(node INode, path []INode) WalkAction {
+			trace = append(trace, node.NodeType())
+			return Continue
+		})
+		Walk(tree("a", tree("b")), nil, leave.Visit)
+		Expect(trace).To(Equal([]string{"b", "a"}))
+	})
+})